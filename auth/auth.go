@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type Role string
+
+const (
+	RoleAdmin Role = "admin" // Can see and manage all commands
+	RoleUser  Role = "user"  // Can only see and manage their own commands
+)
+
+// DefaultUsers is a hardcoded mapping of client certificate common names to their roles
+var DefaultUsers = map[string]Role{
+	"admin": RoleAdmin,
+	"alice": RoleUser,
+	"bob":   RoleUser,
+}
+
+// Identity describes an authenticated client
+type Identity struct {
+	Name string // Client certificate common name
+	Role Role
+}
+
+// CanAccess returns true if the client is allowed to see and manage a command owned by a given user
+func (i *Identity) CanAccess(owner string) bool {
+	return i.Role == RoleAdmin || i.Name == owner
+}
+
+// Authorizer resolves client identities based on their certificates
+type Authorizer struct {
+	users map[string]Role // Known users and their roles, keyed by common name
+}
+
+// NewAuthorizer creates an authorizer for a given set of users
+func NewAuthorizer(users map[string]Role) *Authorizer {
+	return &Authorizer{users: users}
+}
+
+// Authenticate extracts the client certificate common name from the GRPC peer and resolves the client role
+func (a *Authorizer) Authenticate(ctx context.Context) (*Identity, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "no peer information")
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+
+	name := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	role, found := a.users[name]
+	if !found {
+		return nil, status.Errorf(codes.PermissionDenied, "unknown client '%s'", name)
+	}
+
+	return &Identity{Name: name, Role: role}, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+type identityKey struct{}
+
+// WithIdentity returns a context carrying a given client identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the client identity stored in the context (nil if there is none)
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"teleport-exec/remote_exec"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Returns a context of a GRPC peer with a verified client certificate carrying a given common name
+func peerContext(commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		},
+	})
+}
+
+// A fake ownership lookup knowing about a single command owned by alice
+func lookup(commandId string) (string, bool) {
+	if commandId == "alice-cmd" {
+		return "alice", true
+	}
+	return "", false
+}
+
+func TestAuthorizer(t *testing.T) {
+	authorizer := NewAuthorizer(DefaultUsers)
+
+	Convey("Authenticate()", t, func() {
+		Convey("Should resolve the role of a known client", func() {
+			identity, err := authorizer.Authenticate(peerContext("admin"))
+			So(err, ShouldBeNil)
+			So(identity.Name, ShouldEqual, "admin")
+			So(identity.Role, ShouldEqual, RoleAdmin)
+		})
+
+		Convey("Should reject unknown clients", func() {
+			_, err := authorizer.Authenticate(peerContext("mallory"))
+			So(status.Code(err), ShouldEqual, codes.PermissionDenied)
+		})
+
+		Convey("Should reject clients without a verified certificate", func() {
+			_, err := authorizer.Authenticate(context.Background())
+			So(status.Code(err), ShouldEqual, codes.Unauthenticated)
+
+			ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+			_, err = authorizer.Authenticate(ctx)
+			So(status.Code(err), ShouldEqual, codes.Unauthenticated)
+		})
+	})
+
+	Convey("UnaryInterceptor()", t, func() {
+		interceptor := authorizer.UnaryInterceptor(lookup)
+		info := &grpc.UnaryServerInfo{}
+
+		Convey("Should pass the client identity to the handler", func() {
+			var identity *Identity
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				identity = IdentityFromContext(ctx)
+				return nil, nil
+			}
+
+			_, err := interceptor(peerContext("bob"), &remote_exec.StatusRequest{}, info, handler)
+			So(err, ShouldBeNil)
+			So(identity.Name, ShouldEqual, "bob")
+		})
+
+		Convey("Should only allow owners and admins to access a command", func() {
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+			req := &remote_exec.CommandStatusRequest{CommandId: "alice-cmd"}
+
+			_, err := interceptor(peerContext("alice"), req, info, handler)
+			So(err, ShouldBeNil)
+
+			_, err = interceptor(peerContext("admin"), req, info, handler)
+			So(err, ShouldBeNil)
+
+			_, err = interceptor(peerContext("bob"), req, info, handler)
+			So(status.Code(err), ShouldEqual, codes.NotFound)
+		})
+
+		Convey("Should filter the list of commands in the server status", func() {
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &remote_exec.StatusResponse{Commands: []*remote_exec.CommandStatusResponse{
+					{CommandId: "alice-cmd"},
+					{CommandId: "other-cmd"},
+				}}, nil
+			}
+
+			res, _ := interceptor(peerContext("alice"), &remote_exec.StatusRequest{}, info, handler)
+			So(res.(*remote_exec.StatusResponse).Commands, ShouldHaveLength, 1)
+
+			res, _ = interceptor(peerContext("bob"), &remote_exec.StatusRequest{}, info, handler)
+			So(res.(*remote_exec.StatusResponse).Commands, ShouldBeEmpty)
+
+			res, _ = interceptor(peerContext("admin"), &remote_exec.StatusRequest{}, info, handler)
+			So(res.(*remote_exec.StatusResponse).Commands, ShouldHaveLength, 2)
+		})
+	})
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+
+	"teleport-exec/remote_exec"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OwnerLookup returns the owner of a given command (found is false for unknown commands)
+type OwnerLookup func(commandId string) (owner string, found bool)
+
+// Implemented by all requests targeting a specific command
+type commandRequest interface {
+	GetCommandId() string
+}
+
+// UnaryInterceptor authenticates the client, checks command ownership for command-specific requests
+// and filters the list of commands returned by the Status call
+func (a *Authorizer) UnaryInterceptor(lookup OwnerLookup) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		identity, err := a.Authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkOwnership(identity, req, lookup)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := handler(WithIdentity(ctx, identity), req)
+		if err != nil {
+			return nil, err
+		}
+
+		if statusRes, ok := res.(*remote_exec.StatusResponse); ok {
+			statusRes.Commands = filterCommands(identity, statusRes.Commands, lookup)
+		}
+		return res, nil
+	}
+}
+
+// StreamInterceptor authenticates the client and checks command ownership for streaming requests
+func (a *Authorizer) StreamInterceptor(lookup OwnerLookup) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		identity, err := a.Authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authorizedStream{
+			ServerStream: stream,
+			ctx:          WithIdentity(stream.Context(), identity),
+			identity:     identity,
+			lookup:       lookup,
+		})
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+// A server stream wrapper carrying the client identity and checking ownership of received requests
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	identity *Identity
+	lookup   OwnerLookup
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(msg interface{}) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err != nil {
+		return err
+	}
+	return checkOwnership(s.identity, msg, s.lookup)
+}
+
+//-------------------------------------------------------------------------------------------------
+// Makes sure the client is allowed to access a command targeted by the request (if any).
+// Commands owned by other users are reported as missing to avoid leaking their existence.
+func checkOwnership(identity *Identity, req interface{}, lookup OwnerLookup) error {
+	cmdReq, ok := req.(commandRequest)
+	if !ok {
+		return nil
+	}
+
+	owner, found := lookup(cmdReq.GetCommandId())
+	if found && !identity.CanAccess(owner) {
+		return status.Errorf(codes.NotFound, "command %s not found", cmdReq.GetCommandId())
+	}
+	return nil
+}
+
+// Returns only the commands the client is allowed to see
+func filterCommands(identity *Identity, commands []*remote_exec.CommandStatusResponse, lookup OwnerLookup) []*remote_exec.CommandStatusResponse {
+	if identity.Role == RoleAdmin {
+		return commands
+	}
+
+	visible := make([]*remote_exec.CommandStatusResponse, 0, len(commands))
+	for _, cmd := range commands {
+		owner, found := lookup(cmd.CommandId)
+		if found && identity.CanAccess(owner) {
+			visible = append(visible, cmd)
+		}
+	}
+	return visible
+}
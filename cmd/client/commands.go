@@ -0,0 +1,550 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"teleport-exec/remote_exec"
+
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// How many times to try resuming an interrupted output stream, and how long to wait between attempts
+const (
+	maxStreamRetries = 5
+	streamRetryDelay = time.Second
+)
+
+// An error returned by commands that need the client to exit with a specific code
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
+// Reports an error to the user and exits with an appropriate code
+func exitWithError(err error) {
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
+	}
+
+	if st, ok := status.FromError(err); ok {
+		fmt.Fprintf(os.Stderr, "Error: %s (%s)\n", st.Message(), st.Code())
+	} else {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	os.Exit(1)
+}
+
+//-------------------------------------------------------------------------------------------------
+// Shows remote server status
+func serverStatusCommand(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error {
+	res, err := client.Status(ctx, &remote_exec.StatusRequest{})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Server version:", res.Version)
+	fmt.Println("Server PID:", res.Pid)
+	fmt.Println("Commands:", len(res.Commands))
+	for _, cmd := range res.Commands {
+		fmt.Printf("  %s  %-8s  %s\n", cmd.CommandId, commandState(cmd), cmd.Command)
+	}
+	return nil
+}
+
+// Runs a remote command, streams its output and exits with the remote result code
+func runCommand(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error {
+	req, err := parseStartCommand("run", args)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.StartCommand(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	err = streamOutput(ctx, client, &remote_exec.CommandOutputRequest{CommandId: res.CommandId, Tail: true}, false)
+	if err != nil {
+		return err
+	}
+
+	res, err = client.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: res.CommandId})
+	if err != nil {
+		return err
+	}
+	if res.Lost {
+		return fmt.Errorf("command %s was lost when the server restarted, its result is unknown", res.CommandId)
+	}
+	if !res.GetExited() {
+		return &exitCodeError{code: killedExitCode(res.Signal)}
+	}
+	if res.GetResultCode() != 0 {
+		return &exitCodeError{code: int(res.GetResultCode())}
+	}
+	return nil
+}
+
+// Starts a remote command asynchronously and prints its id
+func startCommand(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error {
+	req, err := parseStartCommand("start", args)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.StartCommand(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(res.CommandId)
+	return nil
+}
+
+// Shows the status of a remote command
+func statusCommand(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: status <command_id>")
+	}
+
+	res, err := client.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: args[0]})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Command ID:", res.CommandId)
+	fmt.Println("Command:", res.Command)
+	fmt.Println("Owner:", res.Owner)
+	fmt.Println("Init PID:", res.Pid)
+	fmt.Println("State:", commandState(res))
+	if res.ResultCode != nil {
+		fmt.Println("Result code:", res.GetResultCode())
+	}
+	if res.Signal != "" {
+		fmt.Println("Signal:", res.Signal)
+	}
+	if res.OomKilled {
+		fmt.Println("Killed for running out of memory")
+	}
+
+	fmt.Println("Started:", res.StartTime.AsTime().Local().Format(time.RFC3339))
+	if res.EndTime != nil {
+		fmt.Println("Finished:", res.EndTime.AsTime().Local().Format(time.RFC3339))
+	}
+	if res.Duration != nil {
+		fmt.Println("Duration:", res.Duration.AsDuration().Round(time.Millisecond))
+	}
+
+	if res.Usage != nil {
+		fmt.Println("Peak memory:", res.Usage.PeakMemoryBytes, "bytes")
+		fmt.Println("CPU time:", time.Duration(res.Usage.CpuTimeUsec)*time.Microsecond)
+		fmt.Println("IO read:", res.Usage.IoReadBytes, "bytes")
+		fmt.Println("IO written:", res.Usage.IoWriteBytes, "bytes")
+	}
+	return nil
+}
+
+// Stops a remote command, either killing it right away or sending a signal and killing it after a grace period
+func killCommand(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error {
+	flags := flag.NewFlagSet("kill", flag.ContinueOnError)
+	signal := flags.String("signal", "", "Signal to send first: TERM, INT, HUP, QUIT, USR1 or USR2 (the command is killed right away if empty)")
+	grace := flags.Duration("grace", 0, "How long to wait for the command to finish after the signal before killing it (server default if 0)")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: kill [-signal NAME] [-grace DURATION] <command_id>")
+	}
+
+	req := &remote_exec.StopCommandRequest{CommandId: flags.Arg(0), Signal: *signal}
+	if *grace > 0 {
+		req.GracePeriod = durationpb.New(*grace)
+	}
+
+	_, err = client.StopCommand(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if *signal != "" {
+		fmt.Println("Signal sent to command:", flags.Arg(0))
+	} else {
+		fmt.Println("Command stopped:", flags.Arg(0))
+	}
+	return nil
+}
+
+// Deletes a finished remote command along with its output
+func deleteCommand(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: delete <command_id>")
+	}
+
+	_, err := client.DeleteCommand(ctx, &remote_exec.DeleteCommandRequest{CommandId: args[0]})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Command deleted:", args[0])
+	return nil
+}
+
+// Shows the output of a remote command, optionally following it until the command finishes
+func logsCommand(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error {
+	flags := flag.NewFlagSet("logs", flag.ContinueOnError)
+	tail := flags.Bool("tail", false, "Keep streaming the output until the command finishes")
+	streamName := flags.String("stream", "all", "Output stream to show: stdout, stderr or all")
+	offset := flags.Int64("offset", 0, "Log offset to start from (printed when streaming is interrupted)")
+	skipStdout := flags.Int64("skip-stdout", 0, "Bytes of stdout at the offset already shown (printed when streaming is interrupted)")
+	skipStderr := flags.Int64("skip-stderr", 0, "Bytes of stderr at the offset already shown (printed when streaming is interrupted)")
+	lastLines := flags.Int64("n", 0, "Only show the last N lines of the output")
+	lastBytes := flags.Int64("c", 0, "Only show the last N bytes of the output")
+	lines := flags.Bool("lines", false, "Receive the output line by line, so that lines of stdout and stderr don't mix")
+	timestamps := flags.Bool("timestamps", false, "Prefix each line with the time it was printed (implies -lines)")
+	maxLineLength := flags.Int("max-line-length", 0, "Split longer lines in the line mode (64 KiB by default)")
+	lineTimeout := flags.Duration("line-timeout", 0, "Show a partial line after no output for this long in the line mode (1s by default)")
+	since := flags.String("since", "", "Only show the output printed since a given time (RFC 3339) or duration ago (e.g. 10m)")
+	until := flags.String("until", "", "Only show the output printed until a given time (RFC 3339) or duration ago (e.g. 10m)")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: logs [-tail] [-stream stdout|stderr|all] [-offset N [-skip-stdout N] [-skip-stderr N] | -n N | -c N] " +
+			"[-lines] [-timestamps] [-max-line-length N] [-line-timeout DURATION] [-since T] [-until T] <command_id>")
+	}
+
+	sinceTime, err := parseTime(*since)
+	if err != nil {
+		return err
+	}
+	untilTime, err := parseTime(*until)
+	if err != nil {
+		return err
+	}
+
+	outputStream, ok := remote_exec.OutputStream_value[strings.ToUpper(*streamName)]
+	if !ok {
+		return fmt.Errorf("unknown output stream '%s'", *streamName)
+	}
+
+	req := &remote_exec.CommandOutputRequest{
+		CommandId:       flags.Arg(0),
+		Tail:            *tail,
+		Stream:          remote_exec.OutputStream(outputStream),
+		StartOffset:     *offset,
+		StartSkipStdout: *skipStdout,
+		StartSkipStderr: *skipStderr,
+		LastLines:       *lastLines,
+		LastBytes:       *lastBytes,
+		Lines:           *lines || *timestamps,
+		MaxLineLength:   int32(*maxLineLength),
+		Since:           sinceTime,
+		Until:           untilTime,
+	}
+	if *lineTimeout != 0 {
+		req.LineIdleTimeout = durationpb.New(*lineTimeout)
+	}
+	return streamOutput(ctx, client, req, *timestamps)
+}
+
+// Shows the resource usage of a running remote command, refreshed until the command finishes
+func topCommand(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error {
+	flags := flag.NewFlagSet("top", flag.ContinueOnError)
+	interval := flags.Duration("interval", time.Second, "How often to refresh the stats")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: top [-interval DURATION] <command_id>")
+	}
+
+	stream, err := client.WatchCommandStats(ctx, &remote_exec.WatchCommandStatsRequest{
+		CommandId: flags.Arg(0),
+		Interval:  durationpb.New(*interval),
+	})
+	if err != nil {
+		return err
+	}
+
+	// On a terminal the view is redrawn in place, otherwise each sample is printed on its own line
+	redraw := isTerminal(os.Stdout)
+	var previous *remote_exec.CommandStats
+	for {
+		stats, err := stream.Recv()
+		if err == io.EOF {
+			fmt.Println("Command finished:", flags.Arg(0))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if redraw {
+			fmt.Print("\033[H\033[2J")
+			fmt.Println("Command:", flags.Arg(0))
+			fmt.Println()
+		}
+		if redraw || previous == nil {
+			fmt.Printf("%-8s  %10s  %6s  %10s  %10s  %5s\n", "TIME", "MEMORY", "CPU%", "READ", "WRITTEN", "PIDS")
+		}
+		fmt.Printf("%-8s  %10s  %6.1f  %10s  %10s  %5d\n",
+			stats.Time.AsTime().Local().Format("15:04:05"),
+			formatBytes(stats.MemoryBytes),
+			cpuPercent(previous, stats),
+			formatBytes(stats.IoReadBytes),
+			formatBytes(stats.IoWriteBytes),
+			stats.Pids,
+		)
+		previous = stats
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+// Parses arguments of the run/start commands: optional flags followed by the command
+func parseStartCommand(name string, args []string) (*remote_exec.StartCommandRequest, error) {
+	var env stringList
+	limits := &remote_exec.ResourceLimits{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	workingDir := flags.String("dir", "", "Working directory for the command")
+	stdinFile := flags.String("stdin", "", "File to feed into the command stdin (use - for the client stdin)")
+	flags.Var(&env, "env", "Environment variable for the command in the KEY=VALUE format (may be repeated)")
+	flags.Var(optionalInt64{&limits.MemoryBytes}, "memory", "Memory limit in bytes")
+	flags.Var(optionalInt64{&limits.CpuQuotaUsec}, "cpu-quota", "CPU time within a CPU period in microseconds")
+	flags.Var(optionalInt64{&limits.CpuPeriodUsec}, "cpu-period", "CPU period in microseconds")
+	flags.Var(optionalInt64{&limits.IoReadBps}, "io-read-bps", "Disk read limit in bytes per second")
+	flags.Var(optionalInt64{&limits.IoWriteBps}, "io-write-bps", "Disk write limit in bytes per second")
+	flags.Var(optionalInt64{&limits.IoReadIops}, "io-read-iops", "Disk read limit in operations per second")
+	flags.Var(optionalInt64{&limits.IoWriteIops}, "io-write-iops", "Disk write limit in operations per second")
+	flags.Var(optionalInt64{&limits.PidsMax}, "pids", "Maximum number of processes")
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() < 1 {
+		return nil, fmt.Errorf("usage: %s [flags] <command> [args]", name)
+	}
+
+	req := &remote_exec.StartCommandRequest{
+		Command:    flags.Args(),
+		WorkingDir: *workingDir,
+		Env:        env,
+	}
+
+	// Only send the limits explicitly set by the user, the server uses its defaults for the rest
+	flags.Visit(func(f *flag.Flag) {
+		if _, ok := f.Value.(optionalInt64); ok {
+			req.Limits = limits
+		}
+	})
+
+	if *stdinFile != "" {
+		req.Stdin, err = readStdinFile(*stdinFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// Reads the content to be fed into the command stdin
+func readStdinFile(fileName string) ([]byte, error) {
+	if fileName == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(fileName)
+}
+
+// A flag value collecting all values of a repeated flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// A flag value for optional proto fields, which are only set when the flag is used
+type optionalInt64 struct {
+	field **int64
+}
+
+func (o optionalInt64) String() string {
+	if o.field == nil || *o.field == nil {
+		return ""
+	}
+	return strconv.FormatInt(**o.field, 10)
+}
+
+func (o optionalInt64) Set(value string) error {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+	*o.field = &parsed
+	return nil
+}
+
+// Streams the output of a remote command to stdout/stderr, matching the stream it came from.
+// If the connection is lost, streaming is resumed from the last block received.
+func streamOutput(ctx context.Context, client remote_exec.RemoteExecClient, req *remote_exec.CommandOutputRequest, timestamps bool) error {
+	retries := 0
+	for {
+		position := resumeFlags(req)
+		err := receiveOutput(ctx, client, req, timestamps)
+		if resumeFlags(req) != position {
+			retries = 0 // Only give up if we keep failing without making any progress
+		}
+		if status.Code(err) != codes.Unavailable || retries >= maxStreamRetries {
+			if err != nil && req.StartOffset > 0 {
+				fmt.Fprintln(os.Stderr, "Output interrupted, resume with:", resumeFlags(req))
+			}
+			return err
+		}
+
+		retries++
+		time.Sleep(streamRetryDelay)
+	}
+}
+
+// Receives output blocks until the stream ends, advancing the request offset with each block
+func receiveOutput(ctx context.Context, client remote_exec.RemoteExecClient, req *remote_exec.CommandOutputRequest, timestamps bool) error {
+	stream, err := client.CommandOutput(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		block, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		out := os.Stdout
+		if block.Stream == remote_exec.OutputStream_STDERR {
+			out = os.Stderr
+		}
+		if timestamps {
+			fmt.Fprint(out, block.Time.AsTime().Local().Format(time.RFC3339Nano), " ")
+		}
+		out.Write(block.Output)
+
+		// Once we know the offset, resuming must continue from it rather than from the last lines/bytes
+		req.StartOffset = block.Offset
+		req.StartSkipStdout = block.SkipStdout
+		req.StartSkipStderr = block.SkipStderr
+		req.LastLines = 0
+		req.LastBytes = 0
+	}
+}
+
+// Returns the logs flags which resume streaming from where a given request has stopped
+func resumeFlags(req *remote_exec.CommandOutputRequest) string {
+	flags := fmt.Sprintf("-offset %d", req.StartOffset)
+	if req.StartSkipStdout > 0 {
+		flags += fmt.Sprintf(" -skip-stdout %d", req.StartSkipStdout)
+	}
+	if req.StartSkipStderr > 0 {
+		flags += fmt.Sprintf(" -skip-stderr %d", req.StartSkipStderr)
+	}
+	return flags
+}
+
+// Parses a time given either as an RFC 3339 timestamp or a duration before now (nil if empty)
+func parseTime(value string) (*timestamppb.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return timestamppb.New(time.Now().Add(-duration)), nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time '%s': expected an RFC 3339 time or a duration", value)
+	}
+	return timestamppb.New(parsed), nil
+}
+
+// Returns the share of a single CPU used between two samples (0 for the first sample)
+func cpuPercent(previous *remote_exec.CommandStats, current *remote_exec.CommandStats) float64 {
+	if previous == nil {
+		return 0
+	}
+
+	elapsed := current.Time.AsTime().Sub(previous.Time.AsTime())
+	if elapsed <= 0 {
+		return 0
+	}
+	used := time.Duration(current.CpuTimeUsec-previous.CpuTimeUsec) * time.Microsecond
+	return 100 * float64(used) / float64(elapsed)
+}
+
+// Formats a number of bytes using binary units (e.g. 1.5M)
+func formatBytes(bytes int64) string {
+	value := float64(bytes)
+	for _, unit := range []string{"B", "K", "M", "G"} {
+		if value < 1024 {
+			return fmt.Sprintf("%.1f%s", value, unit)
+		}
+		value /= 1024
+	}
+	return fmt.Sprintf("%.1fT", value)
+}
+
+// Returns true if a given file is a terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Returns the exit code for a command killed by a given signal, following the shell convention of 128 + signal number
+func killedExitCode(signalName string) int {
+	signal := unix.SignalNum(signalName)
+	if signal == 0 {
+		signal = unix.SIGKILL // The signal is not reported by older servers
+	}
+	return 128 + int(signal)
+}
+
+// Returns a human-readable state of a command
+func commandState(cmd *remote_exec.CommandStatusResponse) string {
+	switch {
+	case cmd.Running:
+		return "running"
+	case cmd.Lost:
+		return "lost"
+	case cmd.GetExited():
+		return "exited"
+	default:
+		return "killed"
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"teleport-exec/mtls"
+	"teleport-exec/remote_exec"
+
+	"google.golang.org/grpc"
+)
+
+// How long we wait for the connection to the server to be established
+const dialTimeout = 5 * time.Second
+
+// A function implementing a single CLI command
+type commandFunc func(ctx context.Context, client remote_exec.RemoteExecClient, args []string) error
+
+var commands = map[string]commandFunc{
+	"server-status": serverStatusCommand,
+	"run":           runCommand,
+	"start":         startCommand,
+	"status":        statusCommand,
+	"kill":          killCommand,
+	"delete":        deleteCommand,
+	"logs":          logsCommand,
+	"top":           topCommand,
+}
+
+//-------------------------------------------------------------------------------------------------
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] <command> [args]\n\n", os.Args[0])
+	fmt.Fprintln(out, "Commands:")
+	fmt.Fprintln(out, "  server-status                    Show remote server status")
+	fmt.Fprintln(out, "  run [flags] <command> [args]     Run a remote command, stream its output and exit with its result code")
+	fmt.Fprintln(out, "                                   (128 + signal number if the command was killed by a signal)")
+	fmt.Fprintln(out, "  start [flags] <command> [args]   Start a remote command asynchronously and print its id")
+	fmt.Fprintln(out, "  status <command_id>              Show the status of a remote command")
+	fmt.Fprintln(out, "  kill [flags] <command_id>        Stop a remote command")
+	fmt.Fprintln(out, "  delete <command_id>              Delete a finished remote command and its output")
+	fmt.Fprintln(out, "  logs [flags] <command_id>        Show the output of a remote command")
+	fmt.Fprintln(out, "  top [-interval <d>] <command_id> Show the resource usage of a running remote command")
+	fmt.Fprintln(out, "\nRun/start flags:")
+	fmt.Fprintln(out, "  -dir <path>        Working directory for the command")
+	fmt.Fprintln(out, "  -env KEY=VALUE     Environment variable for the command (may be repeated)")
+	fmt.Fprintln(out, "  -stdin <file>      File to feed into the command stdin (use - for the client stdin)")
+	fmt.Fprintln(out, "  -memory, -cpu-quota, -cpu-period, -io-read-bps, -io-write-bps, -io-read-iops, -io-write-iops, -pids")
+	fmt.Fprintln(out, "                     Resource limits (the server defaults are used for limits not specified)")
+	fmt.Fprintln(out, "\nKill flags:")
+	fmt.Fprintln(out, "  -signal <name>     Signal to send first: TERM, INT, HUP, QUIT, USR1 or USR2 (the command is killed right away by default)")
+	fmt.Fprintln(out, "  -grace <duration>  How long to wait for the command to finish after the signal before killing it")
+	fmt.Fprintln(out, "\nLogs flags:")
+	fmt.Fprintln(out, "  -tail              Keep streaming the output until the command finishes")
+	fmt.Fprintln(out, "  -stream <name>     Output stream to show: stdout, stderr or all (default)")
+	fmt.Fprintln(out, "  -offset <n>        Log offset to start from (to resume an interrupted output)")
+	fmt.Fprintln(out, "  -skip-stdout <n>, -skip-stderr <n>")
+	fmt.Fprintln(out, "                     Bytes of stdout/stderr at the offset already shown (to resume an interrupted output)")
+	fmt.Fprintln(out, "  -n <lines>         Only show the last N lines of the output")
+	fmt.Fprintln(out, "  -c <bytes>         Only show the last N bytes of the output")
+	fmt.Fprintln(out, "  -lines             Receive whole lines, so that lines of stdout and stderr don't mix")
+	fmt.Fprintln(out, "  -timestamps        Prefix each line with the time it was printed")
+	fmt.Fprintln(out, "  -max-line-length <n>, -line-timeout <duration>")
+	fmt.Fprintln(out, "                     Split longer lines (64 KiB by default) and show a partial line after no output")
+	fmt.Fprintln(out, "                     for a while (1s by default) when receiving whole lines")
+	fmt.Fprintln(out, "  -since, -until     Only show the output printed within a time range (RFC 3339 time or duration ago)")
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
+
+//-------------------------------------------------------------------------------------------------
+func main() {
+	addr := flag.String("addr", "localhost:4242", "Address of the server")
+	caFile := flag.String("ca", "certs/ca.crt", "CA certificate used to verify the server certificate")
+	certFile := flag.String("cert", "certs/alice.crt", "Client certificate")
+	keyFile := flag.String("key", "certs/alice.key", "Client certificate key")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	command, found := commands[flag.Arg(0)]
+	if !found {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	creds, err := mtls.ClientCredentials(*caFile, *certFile, *keyFile)
+	if err != nil {
+		log.Fatalln("Failed to load TLS credentials:", err)
+	}
+
+	dialCtx, dialCancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer dialCancel()
+	conn, err := grpc.DialContext(dialCtx, *addr, grpc.WithTransportCredentials(creds), grpc.WithBlock())
+	if err != nil {
+		log.Fatalln("Failed to connect to the server:", err)
+	}
+	defer conn.Close()
+
+	// Stop whatever we're doing when interrupted via Ctrl+C
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	err = command(ctx, remote_exec.NewRemoteExecClient(conn), flag.Args()[1:])
+	if err != nil {
+		cancel()
+		conn.Close()
+		exitWithError(err)
+	}
+}
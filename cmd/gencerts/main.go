@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"teleport-exec/mtls/certgen"
+)
+
+//-------------------------------------------------------------------------------------------------
+func main() {
+	outDir := flag.String("out", "certs", "Directory to write certificates and keys into")
+	clients := flag.String("clients", "admin,alice,bob", "Comma-separated list of client common names to issue certificates for")
+	flag.Parse()
+
+	err := os.MkdirAll(*outDir, 0700)
+	if err != nil {
+		log.Fatalln("Failed to create the output directory:", err)
+	}
+
+	ca, err := certgen.NewCA("teleport-exec-ca")
+	if err != nil {
+		log.Fatalln("Failed to generate a CA:", err)
+	}
+
+	caFile, err := ca.WriteCA(*outDir)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	log.Println("Generated CA certificate:", caFile)
+
+	certFile, _, err := ca.WriteCert(*outDir, "server", true)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	log.Println("Generated server certificate:", certFile)
+
+	for _, name := range strings.Split(*clients, ",") {
+		certFile, _, err := ca.WriteCert(*outDir, strings.TrimSpace(name), false)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		log.Println("Generated client certificate:", certFile)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"teleport-exec/auth"
+	"teleport-exec/container_exec"
+	"teleport-exec/mtls"
+	"teleport-exec/remote_exec"
+
+	"google.golang.org/grpc"
+)
+
+// How often finished commands are checked against the retention settings
+const reapInterval = time.Minute
+
+//-------------------------------------------------------------------------------------------------
+func main() {
+	// When re-executed to start a command, we turn into that command here
+	if container_exec.Init() {
+		return
+	}
+
+	addr := flag.String("addr", "localhost:4242", "Address to listen on")
+	logsDir := flag.String("logs-dir", "/tmp/teleport-exec/logs", "Directory used to store command logs")
+	stateDir := flag.String("state-dir", "/tmp/teleport-exec/state", "Directory used to persist the state of commands across restarts")
+	logSegmentSize := flag.Int64("log-segment-size", 16*1024*1024, "Size in bytes at which a command log is rotated into a new segment (0 to disable rotation)")
+	maxLogSize := flag.Int64("max-log-size", 64*1024*1024, "Maximum size in bytes of a command log, the oldest segments are removed beyond that (0 for no limit, requires rotation)")
+	maxCommandAge := flag.Duration("max-command-age", 24*time.Hour, "How long finished commands and their logs are kept (0 to keep them forever)")
+	maxFinished := flag.Int("max-finished", 1000, "Maximum number of finished commands kept, the oldest ones are removed beyond that (0 for no limit)")
+	caFile := flag.String("ca", "certs/ca.crt", "CA certificate used to verify client certificates")
+	certFile := flag.String("cert", "certs/server.crt", "Server certificate")
+	keyFile := flag.String("key", "certs/server.key", "Server certificate key")
+	cgroupRoot := flag.String("cgroup-root", "/sys/fs/cgroup/teleport-exec", "Parent cgroup for all commands (empty to disable resource limits)")
+	ioDevices := flag.String("io-devices", "", "Comma-separated list of block devices (major:minor) to apply IO limits to (defaults to the disk holding the logs)")
+	limits := container_exec.DefaultLimits
+	limitsFlags(&limits, "", "for each command")
+	maxLimits := container_exec.DefaultMaxLimits
+	limitsFlags(&maxLimits, "max-", "allowed to be requested for a command")
+	flag.Parse()
+
+	creds, err := mtls.ServerCredentials(*caFile, *certFile, *keyFile)
+	if err != nil {
+		log.Fatalln("Failed to load TLS credentials:", err)
+	}
+
+	if *ioDevices != "" {
+		limits.IODevices = strings.Split(*ioDevices, ",")
+	} else if *cgroupRoot != "" {
+		limits.IODevices = detectIODevices(*logsDir)
+	}
+
+	processManager, err := container_exec.NewProcessManager(container_exec.Config{
+		LogsDir:        *logsDir,
+		StateDir:       *stateDir,
+		LogSegmentSize: *logSegmentSize,
+		MaxLogSize:     *maxLogSize,
+		MaxCommandAge:  *maxCommandAge,
+		MaxFinished:    *maxFinished,
+		CgroupRoot:     *cgroupRoot,
+		Limits:         limits,
+		MaxLimits:      maxLimits,
+	})
+	if err != nil {
+		log.Fatalln("Failed to initialize the process manager:", err)
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalln("Failed to listen:", err)
+	}
+
+	service := newRemoteExecService(processManager)
+	authorizer := auth.NewAuthorizer(auth.DefaultUsers)
+	server := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.UnaryInterceptor(authorizer.UnaryInterceptor(service.commandOwner)),
+		grpc.StreamInterceptor(authorizer.StreamInterceptor(service.commandOwner)),
+	)
+	remote_exec.RegisterRemoteExecServer(server, service)
+
+	// Stop the server when interrupted via Ctrl+C or terminated
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		log.Println("Shutting down the server...")
+		server.Stop() // Streams may be tailing running commands, so we can't wait for them to finish
+	}()
+
+	go reapCommands(ctx, processManager)
+
+	log.Println("Listening on", listener.Addr())
+	err = server.Serve(listener)
+	if err != nil {
+		log.Fatalln("Server failed:", err)
+	}
+}
+
+// Periodically removes the finished commands that have expired until the context is done
+func reapCommands(ctx context.Context, processManager *container_exec.ProcessManager) {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			removed, err := processManager.ReapCommands(now)
+			if len(removed) > 0 {
+				log.Println("Removed expired commands:", len(removed))
+			}
+			if err != nil {
+				log.Println("Failed to remove expired commands:", err)
+			}
+		}
+	}
+}
+
+// Returns the disk holding a given directory, so that IO limits could be applied to it
+func detectIODevices(dir string) []string {
+	// The directory may not exist yet, errors would be reported by the process manager later
+	_ = os.MkdirAll(dir, 0700)
+
+	device, err := container_exec.DetectIODevice(dir)
+	if err != nil {
+		log.Println("IO limits are disabled, failed to detect the disk device:", err)
+		return nil
+	}
+	return []string{device}
+}
+
+// Registers flags for all configurable limits, using a given prefix for flag names
+func limitsFlags(limits *container_exec.Limits, prefix string, description string) {
+	flag.Int64Var(&limits.MemoryBytes, prefix+"memory-limit", limits.MemoryBytes, "Memory limit in bytes "+description+" (0 for no limit)")
+	flag.Int64Var(&limits.CPUQuotaUsec, prefix+"cpu-quota", limits.CPUQuotaUsec, "CPU time within a CPU period in microseconds "+description+" (0 for no limit)")
+	flag.Int64Var(&limits.CPUPeriodUsec, prefix+"cpu-period", limits.CPUPeriodUsec, "CPU period in microseconds "+description)
+	flag.Int64Var(&limits.IOReadBPS, prefix+"io-read-bps", limits.IOReadBPS, "Disk read limit in bytes per second "+description+" (0 for no limit)")
+	flag.Int64Var(&limits.IOWriteBPS, prefix+"io-write-bps", limits.IOWriteBPS, "Disk write limit in bytes per second "+description+" (0 for no limit)")
+	flag.Int64Var(&limits.IOReadIOPS, prefix+"io-read-iops", limits.IOReadIOPS, "Disk read limit in operations per second "+description+" (0 for no limit)")
+	flag.Int64Var(&limits.IOWriteIOPS, prefix+"io-write-iops", limits.IOWriteIOPS, "Disk write limit in operations per second "+description+" (0 for no limit)")
+	flag.Int64Var(&limits.PidsMax, prefix+"pids-limit", limits.PidsMax, "Maximum number of processes "+description+" (0 for no limit)")
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"teleport-exec/auth"
+	"teleport-exec/container_exec"
+	"teleport-exec/filestream"
+	"teleport-exec/logframe"
+	"teleport-exec/remote_exec"
+
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const version = "0.1.0"
+
+// How long a command is given to finish after being asked to stop with a signal other than SIGKILL
+const defaultGracePeriod = 10 * time.Second
+
+// How often resource stats of a command are sampled unless requested otherwise, and how often they could be sampled at most
+const (
+	defaultStatsInterval = time.Second
+	minStatsInterval     = 100 * time.Millisecond
+)
+
+// Maps log streams to the output streams of the API and back (ALL maps to the zero stream matching everything)
+var (
+	outputStreams = map[logframe.Stream]remote_exec.OutputStream{
+		logframe.Stdout: remote_exec.OutputStream_STDOUT,
+		logframe.Stderr: remote_exec.OutputStream_STDERR,
+	}
+	logStreams = map[remote_exec.OutputStream]logframe.Stream{
+		remote_exec.OutputStream_STDOUT: logframe.Stdout,
+		remote_exec.OutputStream_STDERR: logframe.Stderr,
+	}
+)
+
+type remoteExecService struct {
+	remote_exec.UnimplementedRemoteExecServer
+	processManager *container_exec.ProcessManager
+}
+
+// newRemoteExecService creates a RemoteExec GRPC service on top of a given process manager
+func newRemoteExecService(processManager *container_exec.ProcessManager) *remoteExecService {
+	return &remoteExecService{processManager: processManager}
+}
+
+// Status returns basic server information and a list of all known commands
+func (s *remoteExecService) Status(ctx context.Context, req *remote_exec.StatusRequest) (*remote_exec.StatusResponse, error) {
+	commands := s.processManager.Commands()
+	statuses := make([]*remote_exec.CommandStatusResponse, 0, len(commands))
+	for _, cmd := range commands {
+		statuses = append(statuses, commandStatus(cmd))
+	}
+
+	return &remote_exec.StatusResponse{
+		Version:  version,
+		Pid:      int64(os.Getpid()),
+		Commands: statuses,
+	}, nil
+}
+
+// StartCommand starts a new command and returns its initial status
+func (s *remoteExecService) StartCommand(ctx context.Context, req *remote_exec.StartCommandRequest) (*remote_exec.CommandStatusResponse, error) {
+	if len(req.Command) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "command is required")
+	}
+
+	owner := ""
+	if identity := auth.IdentityFromContext(ctx); identity != nil {
+		owner = identity.Name
+	}
+
+	options := container_exec.CommandOptions{
+		Owner:      owner,
+		Limits:     s.requestedLimits(req.Limits),
+		WorkingDir: req.WorkingDir,
+		Env:        req.Env,
+		Stdin:      req.Stdin,
+	}
+
+	cmd, err := s.processManager.StartCommand(req.Command, options)
+	if errors.Is(err, container_exec.ErrInvalidLimits) || errors.Is(err, container_exec.ErrInvalidOptions) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to start command: %v", err)
+	}
+	return commandStatus(cmd), nil
+}
+
+// StopCommand sends a signal to a running command (SIGKILL by default), killing it if it is still running after the grace period
+func (s *remoteExecService) StopCommand(ctx context.Context, req *remote_exec.StopCommandRequest) (*remote_exec.StopCommandResponse, error) {
+	cmd, err := s.findCommand(req.CommandId)
+	if err != nil {
+		return nil, err
+	}
+
+	signal := syscall.SIGKILL
+	if req.Signal != "" {
+		signal, err = container_exec.ParseSignal(req.Signal)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	gracePeriod := defaultGracePeriod
+	if req.GracePeriod != nil {
+		gracePeriod = req.GracePeriod.AsDuration()
+		if gracePeriod < 0 {
+			return nil, status.Error(codes.InvalidArgument, "grace period must not be negative")
+		}
+	}
+
+	err = cmd.Stop(signal, gracePeriod)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to stop command: %v", err)
+	}
+
+	return &remote_exec.StopCommandResponse{
+		CommandId: cmd.Id,
+		Success:   true,
+	}, nil
+}
+
+// DeleteCommand removes a finished command along with its output
+func (s *remoteExecService) DeleteCommand(ctx context.Context, req *remote_exec.DeleteCommandRequest) (*remote_exec.DeleteCommandResponse, error) {
+	cmd, err := s.findCommand(req.CommandId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.processManager.DeleteCommand(cmd.Id)
+	if errors.Is(err, container_exec.ErrCommandRunning) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete command: %v", err)
+	}
+
+	return &remote_exec.DeleteCommandResponse{
+		CommandId: cmd.Id,
+		Success:   true,
+	}, nil
+}
+
+// CommandStatus returns the current status of a given command
+func (s *remoteExecService) CommandStatus(ctx context.Context, req *remote_exec.CommandStatusRequest) (*remote_exec.CommandStatusResponse, error) {
+	cmd, err := s.findCommand(req.CommandId)
+	if err != nil {
+		return nil, err
+	}
+	return commandStatus(cmd), nil
+}
+
+// CommandOutput streams the output of a command from the beginning (or a given offset, or the last lines/bytes),
+// optionally limited to a single stream. In tail mode the stream continues until the command is finished.
+func (s *remoteExecService) CommandOutput(req *remote_exec.CommandOutputRequest, stream remote_exec.RemoteExec_CommandOutputServer) error {
+	cmd, err := s.findCommand(req.CommandId)
+	if err != nil {
+		return err
+	}
+
+	logStream, err := cmd.NewLogStream(stream.Context(), container_exec.LogStreamOptions{
+		Tail:   req.Tail,
+		Stream: logStreams[req.Stream],
+		Offset: req.StartOffset,
+		Skip: map[logframe.Stream]int64{
+			logframe.Stdout: req.StartSkipStdout,
+			logframe.Stderr: req.StartSkipStderr,
+		},
+		LastLines: req.LastLines,
+		LastBytes: req.LastBytes,
+		Lines:     req.Lines,
+		LineOptions: filestream.LineOptions{
+			MaxLength:   int(req.MaxLineLength),
+			IdleTimeout: req.LineIdleTimeout.AsDuration(),
+		},
+		Since: optionalTime(req.Since),
+		Until: optionalTime(req.Until),
+	})
+	if errors.Is(err, container_exec.ErrInvalidOptions) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to open command output: %v", err)
+	}
+	defer cmd.CloseLogStream(logStream)
+
+	for {
+		frame, err := logStream.ReadFrame()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to read command output: %v", err)
+		}
+
+		// Frames and lines are small enough to be sent as a whole, which makes the offset of each block a valid resume point
+		err = stream.Send(&remote_exec.CommandOutputBlock{
+			Output:     frame.Data,
+			Stream:     outputStreams[frame.Stream],
+			Offset:     logStream.Offset(),
+			Time:       timestamppb.New(frame.Time),
+			SkipStdout: logStream.Skip(logframe.Stdout),
+			SkipStderr: logStream.Skip(logframe.Stderr),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// WatchCommandStats streams the resource usage of a running command at a given interval until the command is finished
+func (s *remoteExecService) WatchCommandStats(req *remote_exec.WatchCommandStatsRequest, stream remote_exec.RemoteExec_WatchCommandStatsServer) error {
+	cmd, err := s.findCommand(req.CommandId)
+	if err != nil {
+		return err
+	}
+
+	interval := defaultStatsInterval
+	if req.Interval != nil {
+		interval = req.Interval.AsDuration()
+		if interval < minStatsInterval {
+			return status.Errorf(codes.InvalidArgument, "interval must be at least %v", minStatsInterval)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for first := true; ; first = false {
+		stats, err := cmd.Stats()
+		if errors.Is(err, container_exec.ErrStatsUnavailable) {
+			if !first {
+				return nil // The command has just finished
+			}
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to read command stats: %v", err)
+		}
+
+		err = stream.Send(&remote_exec.CommandStats{
+			Time:         timestamppb.Now(),
+			MemoryBytes:  stats.MemoryBytes,
+			CpuTimeUsec:  stats.CPUTimeUsec,
+			IoReadBytes:  stats.IOReadBytes,
+			IoWriteBytes: stats.IOWriteBytes,
+			Pids:         stats.Pids,
+		})
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-cmd.Done():
+			return nil
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// commandOwner returns the owner of a given command, used for authorization checks
+func (s *remoteExecService) commandOwner(commandId string) (string, bool) {
+	cmd := s.processManager.FindCommand(commandId)
+	if cmd == nil {
+		return "", false
+	}
+	return cmd.Owner, true
+}
+
+//-------------------------------------------------------------------------------------------------
+// Returns the default limits overridden with the values requested by the client (nil if nothing was requested)
+func (s *remoteExecService) requestedLimits(req *remote_exec.ResourceLimits) *container_exec.Limits {
+	if req == nil {
+		return nil
+	}
+
+	limits := s.processManager.DefaultLimits()
+	overrides := []struct {
+		value *int64
+		field *int64
+	}{
+		{req.MemoryBytes, &limits.MemoryBytes},
+		{req.CpuQuotaUsec, &limits.CPUQuotaUsec},
+		{req.CpuPeriodUsec, &limits.CPUPeriodUsec},
+		{req.IoReadBps, &limits.IOReadBPS},
+		{req.IoWriteBps, &limits.IOWriteBPS},
+		{req.IoReadIops, &limits.IOReadIOPS},
+		{req.IoWriteIops, &limits.IOWriteIOPS},
+		{req.PidsMax, &limits.PidsMax},
+	}
+	for _, override := range overrides {
+		if override.value != nil {
+			*override.field = *override.value
+		}
+	}
+	return &limits
+}
+
+// Returns a command with a given id or a NotFound error
+func (s *remoteExecService) findCommand(commandId string) (*container_exec.Command, error) {
+	cmd := s.processManager.FindCommand(commandId)
+	if cmd == nil {
+		return nil, status.Errorf(codes.NotFound, "command %s not found", commandId)
+	}
+	return cmd, nil
+}
+
+// Converts an optional proto timestamp into time (zero time if not set)
+func optionalTime(timestamp *timestamppb.Timestamp) time.Time {
+	if timestamp == nil {
+		return time.Time{}
+	}
+	return timestamp.AsTime()
+}
+
+// Converts the command state into a status response
+func commandStatus(cmd *container_exec.Command) *remote_exec.CommandStatusResponse {
+	res := &remote_exec.CommandStatusResponse{
+		CommandId: cmd.Id,
+		Command:   strings.Join(cmd.Command, " "),
+		Running:   cmd.Running(),
+		Lost:      cmd.Lost(),
+		Owner:     cmd.Owner,
+		Pid:       int64(cmd.Pid()),
+		StartTime: timestamppb.New(cmd.StartTime()),
+	}
+
+	if res.Running {
+		res.Duration = durationpb.New(time.Since(cmd.StartTime()))
+	}
+
+	if !res.Running && !res.Lost {
+		resultCode := cmd.ResultCode()
+		exited := cmd.Exited()
+		res.ResultCode = &resultCode
+		res.Exited = &exited
+		if signal := cmd.Signal(); signal != 0 {
+			res.Signal = unix.SignalName(signal)
+		}
+
+		res.EndTime = timestamppb.New(cmd.EndTime())
+		res.Duration = durationpb.New(cmd.EndTime().Sub(cmd.StartTime()))
+	}
+
+	if usage := cmd.Usage(); usage != nil {
+		res.OomKilled = usage.OOMKilled
+		res.Usage = &remote_exec.ResourceUsage{
+			PeakMemoryBytes: usage.PeakMemoryBytes,
+			CpuTimeUsec:     usage.CPUTimeUsec,
+			IoReadBytes:     usage.IOReadBytes,
+			IoWriteBytes:    usage.IOWriteBytes,
+		}
+	}
+	return res
+}
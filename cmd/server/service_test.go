@@ -0,0 +1,500 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"teleport-exec/auth"
+	"teleport-exec/container_exec"
+	"teleport-exec/mtls"
+	"teleport-exec/mtls/certgen"
+	"teleport-exec/remote_exec"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Starts an in-memory mTLS server with authorization enabled.
+// Returns a function for connecting to the server as a given user and a cleanup function.
+func startTestServer(dir string) (func(name string) remote_exec.RemoteExecClient, func()) {
+	ca, _ := certgen.NewCA("test-ca")
+	caFile, _ := ca.WriteCA(dir)
+	serverCert, serverKey, _ := ca.WriteCert(dir, "server", true)
+	serverCreds, _ := mtls.ServerCredentials(caFile, serverCert, serverKey)
+
+	processManager, _ := container_exec.NewProcessManager(container_exec.Config{
+		LogsDir:   path.Join(dir, "logs"),
+		Limits:    container_exec.DefaultLimits,
+		MaxLimits: container_exec.DefaultMaxLimits,
+	})
+	service := newRemoteExecService(processManager)
+	authorizer := auth.NewAuthorizer(auth.DefaultUsers)
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer(
+		grpc.Creds(serverCreds),
+		grpc.UnaryInterceptor(authorizer.UnaryInterceptor(service.commandOwner)),
+		grpc.StreamInterceptor(authorizer.StreamInterceptor(service.commandOwner)),
+	)
+	remote_exec.RegisterRemoteExecServer(server, service)
+	go server.Serve(listener)
+
+	var conns []*grpc.ClientConn
+	dialer := func(context.Context, string) (net.Conn, error) { return listener.Dial() }
+	connect := func(name string) remote_exec.RemoteExecClient {
+		clientCert, clientKey, _ := ca.WriteCert(dir, name, false)
+		clientConfig, _ := mtls.ClientConfig(caFile, clientCert, clientKey)
+		clientConfig.ServerName = "localhost"
+
+		conn, _ := grpc.Dial("bufnet", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(credentials.NewTLS(clientConfig)))
+		conns = append(conns, conn)
+		return remote_exec.NewRemoteExecClient(conn)
+	}
+
+	return connect, func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		server.Stop()
+	}
+}
+
+// Reads the whole output of a command via the CommandOutput API
+func readOutput(ctx context.Context, client remote_exec.RemoteExecClient, commandId string) (string, error) {
+	blocks, err := readBlocks(ctx, client, &remote_exec.CommandOutputRequest{CommandId: commandId, Tail: true})
+	output := ""
+	for _, block := range blocks {
+		output += string(block.Output)
+	}
+	return output, err
+}
+
+// Reads all output blocks of a command
+func readBlocks(ctx context.Context, client remote_exec.RemoteExecClient, req *remote_exec.CommandOutputRequest) ([]*remote_exec.CommandOutputBlock, error) {
+	stream, err := client.CommandOutput(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*remote_exec.CommandOutputBlock
+	for {
+		block, err := stream.Recv()
+		if err == io.EOF {
+			return blocks, nil
+		}
+		if err != nil {
+			return blocks, err
+		}
+		blocks = append(blocks, block)
+	}
+}
+
+func TestRemoteExecService(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("RemoteExec service", t, func() {
+		dir, _ := os.MkdirTemp("", "server_test")
+		connect, stop := startTestServer(dir)
+		client := connect("alice")
+
+		Convey("Status() should return server information", func() {
+			res, err := client.Status(ctx, &remote_exec.StatusRequest{})
+			So(err, ShouldBeNil)
+			So(res.Version, ShouldEqual, version)
+			So(res.Pid, ShouldEqual, os.Getpid())
+		})
+
+		Convey("StartCommand()", func() {
+			Convey("Should start a command and stream its output", func() {
+				res, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{Command: []string{"echo", "hello"}})
+				So(err, ShouldBeNil)
+				So(res.CommandId, ShouldNotBeEmpty)
+				So(res.Command, ShouldEqual, "echo hello")
+
+				output, err := readOutput(ctx, client, res.CommandId)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "hello\n")
+
+				res, err = client.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: res.CommandId})
+				So(err, ShouldBeNil)
+				So(res.Running, ShouldBeFalse)
+				So(res.GetExited(), ShouldBeTrue)
+				So(res.GetResultCode(), ShouldEqual, 0)
+				So(res.Owner, ShouldEqual, "alice")
+				So(res.Pid, ShouldBeGreaterThan, 0)
+				So(res.EndTime.AsTime(), ShouldHappenOnOrAfter, res.StartTime.AsTime())
+				So(res.Duration.AsDuration(), ShouldBeGreaterThan, 0)
+			})
+
+			Convey("Should pass the working directory, environment and stdin to the command", func() {
+				res, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command:    []string{"sh", "-c", "pwd; echo $GREETING; cat"},
+					WorkingDir: "/etc",
+					Env:        []string{"GREETING=hello"},
+					Stdin:      []byte("from stdin\n"),
+				})
+				So(err, ShouldBeNil)
+
+				output, err := readOutput(ctx, client, res.CommandId)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "/etc\nhello\nfrom stdin\n")
+			})
+
+			Convey("Should tag the output with the stream it came from", func() {
+				res, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"sh", "-c", "echo out; sleep 0.1; echo err >&2"},
+				})
+				So(err, ShouldBeNil)
+
+				req := &remote_exec.CommandOutputRequest{CommandId: res.CommandId, Tail: true}
+				blocks, err := readBlocks(ctx, client, req)
+				So(err, ShouldBeNil)
+				So(blocks, ShouldHaveLength, 2)
+				So(blocks[0].Stream, ShouldEqual, remote_exec.OutputStream_STDOUT)
+				So(string(blocks[0].Output), ShouldEqual, "out\n")
+				So(blocks[1].Stream, ShouldEqual, remote_exec.OutputStream_STDERR)
+				So(string(blocks[1].Output), ShouldEqual, "err\n")
+
+				Convey("Should only return the output of a requested stream", func() {
+					req.Stream = remote_exec.OutputStream_STDERR
+					blocks, err := readBlocks(ctx, client, req)
+					So(err, ShouldBeNil)
+					So(blocks, ShouldHaveLength, 1)
+					So(string(blocks[0].Output), ShouldEqual, "err\n")
+				})
+			})
+
+			Convey("Should resume the output from the offset of a received block", func() {
+				res, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"sh", "-c", "echo one; sleep 0.1; echo two; sleep 0.1; echo three"},
+				})
+				So(err, ShouldBeNil)
+
+				req := &remote_exec.CommandOutputRequest{CommandId: res.CommandId, Tail: true}
+				blocks, err := readBlocks(ctx, client, req)
+				So(err, ShouldBeNil)
+				So(blocks, ShouldHaveLength, 3)
+
+				req.StartOffset = blocks[0].Offset
+				resumed, err := readBlocks(ctx, client, req)
+				So(err, ShouldBeNil)
+				So(resumed, ShouldHaveLength, 2)
+				So(string(resumed[0].Output), ShouldEqual, "two\n")
+				So(resumed[1].Offset, ShouldEqual, blocks[2].Offset)
+
+				Convey("Should reject offsets outside of the output", func() {
+					req.StartOffset = blocks[2].Offset + 1
+					_, err := readBlocks(ctx, client, req)
+					So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+				})
+
+				Convey("Should reject offsets within an output block", func() {
+					req.StartOffset = blocks[1].Offset + 1
+					_, err := readBlocks(ctx, client, req)
+					So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+				})
+			})
+
+			Convey("Should start the output from the last lines", func() {
+				res, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"sh", "-c", "echo one; echo two; echo three"},
+				})
+				So(err, ShouldBeNil)
+				readOutput(ctx, client, res.CommandId) // Wait for the command to finish
+
+				blocks, err := readBlocks(ctx, client, &remote_exec.CommandOutputRequest{CommandId: res.CommandId, LastLines: 2})
+				So(err, ShouldBeNil)
+				output := ""
+				for _, block := range blocks {
+					output += string(block.Output)
+				}
+				So(output, ShouldEqual, "two\nthree\n")
+
+				Convey("Should not allow combining it with an offset", func() {
+					_, err := readBlocks(ctx, client, &remote_exec.CommandOutputRequest{CommandId: res.CommandId, LastLines: 2, StartOffset: 1})
+					So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+				})
+			})
+
+			Convey("Should send whole lines in the line mode", func() {
+				res, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"sh", "-c", "printf 'hel'; sleep 0.1; echo 'lo'; echo world"},
+				})
+				So(err, ShouldBeNil)
+
+				blocks, err := readBlocks(ctx, client, &remote_exec.CommandOutputRequest{CommandId: res.CommandId, Tail: true, Lines: true})
+				So(err, ShouldBeNil)
+				So(blocks, ShouldHaveLength, 2)
+				So(string(blocks[0].Output), ShouldEqual, "hello\n")
+				So(string(blocks[1].Output), ShouldEqual, "world\n")
+			})
+
+			Convey("Should split lines and resume them exactly in the line mode", func() {
+				res, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"sh", "-c", "printf 'hel'; sleep 0.1; echo oops >&2; sleep 0.1; echo 'lo world'"},
+				})
+				So(err, ShouldBeNil)
+
+				req := &remote_exec.CommandOutputRequest{CommandId: res.CommandId, Tail: true, Lines: true, MaxLineLength: 6}
+				blocks, err := readBlocks(ctx, client, req)
+				So(err, ShouldBeNil)
+				So(blocks, ShouldHaveLength, 3)
+				So(string(blocks[0].Output), ShouldEqual, "oops\n")
+				So(string(blocks[1].Output), ShouldEqual, "hello ")
+				So(blocks[0].SkipStderr, ShouldEqual, 5)
+
+				req.StartOffset = blocks[0].Offset
+				req.StartSkipStdout = blocks[0].SkipStdout
+				req.StartSkipStderr = blocks[0].SkipStderr
+				resumed, err := readBlocks(ctx, client, req)
+				So(err, ShouldBeNil)
+				So(resumed, ShouldHaveLength, 2)
+				So(string(resumed[0].Output), ShouldEqual, "hello ")
+				So(string(resumed[1].Output), ShouldEqual, "world\n")
+
+				Convey("Should reject invalid line options", func() {
+					req.LineIdleTimeout = durationpb.New(-time.Second)
+					_, err := readBlocks(ctx, client, req)
+					So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+				})
+			})
+
+			Convey("Should send the capture time of the output and filter by it", func() {
+				res, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"sh", "-c", "echo one; sleep 0.3; echo two"},
+				})
+				So(err, ShouldBeNil)
+
+				blocks, err := readBlocks(ctx, client, &remote_exec.CommandOutputRequest{CommandId: res.CommandId, Tail: true})
+				So(err, ShouldBeNil)
+				So(blocks, ShouldHaveLength, 2)
+				So(blocks[0].Time.AsTime(), ShouldHappenWithin, time.Minute, time.Now())
+
+				blocks, err = readBlocks(ctx, client, &remote_exec.CommandOutputRequest{CommandId: res.CommandId, Since: blocks[1].Time})
+				So(err, ShouldBeNil)
+				So(blocks, ShouldHaveLength, 1)
+				So(string(blocks[0].Output), ShouldEqual, "two\n")
+			})
+
+			Convey("Should reject invalid environment variables", func() {
+				_, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"true"},
+					Env:     []string{"INVALID"},
+				})
+				So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+			})
+
+			Convey("Should reject empty commands", func() {
+				_, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{})
+				So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+			})
+
+			Convey("Should accept limits within the allowed maximum", func() {
+				memory := container_exec.DefaultMaxLimits.MemoryBytes
+				_, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"true"},
+					Limits:  &remote_exec.ResourceLimits{MemoryBytes: &memory},
+				})
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Should reject limits exceeding the allowed maximum", func() {
+				pids := container_exec.DefaultMaxLimits.PidsMax + 1
+				_, err := client.StartCommand(ctx, &remote_exec.StartCommandRequest{
+					Command: []string{"true"},
+					Limits:  &remote_exec.ResourceLimits{PidsMax: &pids},
+				})
+				So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+			})
+
+			Convey("Should list started commands in the server status", func() {
+				res, _ := client.StartCommand(ctx, &remote_exec.StartCommandRequest{Command: []string{"true"}})
+				serverStatus, err := client.Status(ctx, &remote_exec.StatusRequest{})
+				So(err, ShouldBeNil)
+				So(serverStatus.Commands, ShouldHaveLength, 1)
+				So(serverStatus.Commands[0].CommandId, ShouldEqual, res.CommandId)
+			})
+		})
+
+		Convey("StopCommand() should kill a running command", func() {
+			res, _ := client.StartCommand(ctx, &remote_exec.StartCommandRequest{Command: []string{"sleep", "100"}})
+
+			stopRes, err := client.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: res.CommandId})
+			So(err, ShouldBeNil)
+			So(stopRes.Success, ShouldBeTrue)
+
+			// The output stream finishes once the command is dead
+			_, err = readOutput(ctx, client, res.CommandId)
+			So(err, ShouldBeNil)
+
+			res, _ = client.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: res.CommandId})
+			So(res.Running, ShouldBeFalse)
+			So(res.GetExited(), ShouldBeFalse)
+			So(res.Signal, ShouldEqual, "SIGKILL")
+		})
+
+		Convey("StopCommand() should send a requested signal", func() {
+			res, _ := client.StartCommand(ctx, &remote_exec.StartCommandRequest{Command: []string{"sh", "-c", "trap '' TERM; sleep 100"}})
+			time.Sleep(100 * time.Millisecond) // Give the shell time to set up the trap
+
+			_, err := client.StopCommand(ctx, &remote_exec.StopCommandRequest{
+				CommandId:   res.CommandId,
+				Signal:      "TERM",
+				GracePeriod: durationpb.New(100 * time.Millisecond),
+			})
+			So(err, ShouldBeNil)
+
+			// The signal is ignored, so the command gets killed after the grace period
+			_, err = readOutput(ctx, client, res.CommandId)
+			So(err, ShouldBeNil)
+			res, _ = client.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: res.CommandId})
+			So(res.Signal, ShouldEqual, "SIGKILL")
+
+			Convey("Should reject unknown signals", func() {
+				_, err := client.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: res.CommandId, Signal: "NOPE"})
+				So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+			})
+
+			Convey("Should reject signals which don't reach the command", func() {
+				_, err := client.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: res.CommandId, Signal: "STOP"})
+				So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+			})
+
+			Convey("Should reject a negative grace period", func() {
+				_, err := client.StopCommand(ctx, &remote_exec.StopCommandRequest{
+					CommandId:   res.CommandId,
+					Signal:      "TERM",
+					GracePeriod: durationpb.New(-time.Second),
+				})
+				So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+			})
+		})
+
+		Convey("DeleteCommand()", func() {
+			Convey("Should remove a finished command", func() {
+				res, _ := client.StartCommand(ctx, &remote_exec.StartCommandRequest{Command: []string{"true"}})
+				readOutput(ctx, client, res.CommandId)
+
+				deleteRes, err := client.DeleteCommand(ctx, &remote_exec.DeleteCommandRequest{CommandId: res.CommandId})
+				So(err, ShouldBeNil)
+				So(deleteRes.Success, ShouldBeTrue)
+
+				_, err = client.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: res.CommandId})
+				So(status.Code(err), ShouldEqual, codes.NotFound)
+			})
+
+			Convey("Should refuse to remove a running command", func() {
+				res, _ := client.StartCommand(ctx, &remote_exec.StartCommandRequest{Command: []string{"sleep", "100"}})
+				defer client.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: res.CommandId})
+
+				_, err := client.DeleteCommand(ctx, &remote_exec.DeleteCommandRequest{CommandId: res.CommandId})
+				So(status.Code(err), ShouldEqual, codes.FailedPrecondition)
+			})
+		})
+
+		Convey("WatchCommandStats()", func() {
+			res, _ := client.StartCommand(ctx, &remote_exec.StartCommandRequest{Command: []string{"sleep", "100"}})
+			defer client.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: res.CommandId})
+
+			Convey("Should fail when resource limits are disabled", func() {
+				stream, _ := client.WatchCommandStats(ctx, &remote_exec.WatchCommandStatsRequest{CommandId: res.CommandId})
+				_, err := stream.Recv()
+				So(status.Code(err), ShouldEqual, codes.FailedPrecondition)
+			})
+
+			Convey("Should reject too short intervals", func() {
+				stream, _ := client.WatchCommandStats(ctx, &remote_exec.WatchCommandStatsRequest{
+					CommandId: res.CommandId,
+					Interval:  durationpb.New(time.Millisecond),
+				})
+				_, err := stream.Recv()
+				So(status.Code(err), ShouldEqual, codes.InvalidArgument)
+			})
+		})
+
+		Convey("Should return NotFound for unknown commands", func() {
+			_, err := client.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: "unknown"})
+			So(status.Code(err), ShouldEqual, codes.NotFound)
+
+			_, err = client.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: "unknown"})
+			So(status.Code(err), ShouldEqual, codes.NotFound)
+
+			_, err = readOutput(ctx, client, "unknown")
+			So(status.Code(err), ShouldEqual, codes.NotFound)
+		})
+
+		Convey("Authorization", func() {
+			res, _ := client.StartCommand(ctx, &remote_exec.StartCommandRequest{Command: []string{"sleep", "100"}})
+			defer client.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: res.CommandId})
+
+			Convey("Should reject unknown clients", func() {
+				_, err := connect("mallory").Status(ctx, &remote_exec.StatusRequest{})
+				So(status.Code(err), ShouldEqual, codes.PermissionDenied)
+
+				_, err = readOutput(ctx, connect("mallory"), res.CommandId)
+				So(status.Code(err), ShouldEqual, codes.PermissionDenied)
+			})
+
+			Convey("Should hide commands owned by other users", func() {
+				bob := connect("bob")
+
+				serverStatus, err := bob.Status(ctx, &remote_exec.StatusRequest{})
+				So(err, ShouldBeNil)
+				So(serverStatus.Commands, ShouldBeEmpty)
+
+				_, err = bob.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: res.CommandId})
+				So(status.Code(err), ShouldEqual, codes.NotFound)
+
+				_, err = bob.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: res.CommandId})
+				So(status.Code(err), ShouldEqual, codes.NotFound)
+
+				_, err = bob.DeleteCommand(ctx, &remote_exec.DeleteCommandRequest{CommandId: res.CommandId})
+				So(status.Code(err), ShouldEqual, codes.NotFound)
+
+				stats, _ := bob.WatchCommandStats(ctx, &remote_exec.WatchCommandStatsRequest{CommandId: res.CommandId})
+				_, err = stats.Recv()
+				So(status.Code(err), ShouldEqual, codes.NotFound)
+
+				_, err = readOutput(ctx, bob, res.CommandId)
+				So(status.Code(err), ShouldEqual, codes.NotFound)
+			})
+
+			Convey("Should let admins see and manage all commands", func() {
+				admin := connect("admin")
+
+				serverStatus, err := admin.Status(ctx, &remote_exec.StatusRequest{})
+				So(err, ShouldBeNil)
+				So(serverStatus.Commands, ShouldHaveLength, 1)
+
+				_, err = admin.CommandStatus(ctx, &remote_exec.CommandStatusRequest{CommandId: res.CommandId})
+				So(err, ShouldBeNil)
+
+				_, err = admin.StopCommand(ctx, &remote_exec.StopCommandRequest{CommandId: res.CommandId})
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Reset(func() {
+			stop()
+			os.RemoveAll(dir)
+		})
+	})
+}
+
+// Commands are started by re-executing the test binary, which needs to turn into a container init
+func TestMain(m *testing.M) {
+	if container_exec.Init() {
+		return
+	}
+	os.Exit(m.Run())
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"io"
 	"log"
 	"os"
@@ -12,10 +13,16 @@ import (
 
 //-------------------------------------------------------------------------------------------------
 func main() {
-	if len(os.Args) < 2 {
+	num_lines := flag.Int("n", 0, "Start from the last N lines of the file")
+	num_bytes := flag.Int64("c", 0, "Start from the last N bytes of the file")
+	poll_interval := flag.Duration("poll", 0, "Poll the file with a given interval instead of using inotify")
+	follow_name := flag.Bool("F", false, "Follow the file by name, reopening it when it is truncated, renamed or removed")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
 		log.Fatalln("Need an argument!")
 	}
-	file_name := os.Args[1]
+	file_name := flag.Arg(0)
 	log.Println("Tailing file:", file_name)
 
 	// Timeout tailing after a while
@@ -27,16 +34,34 @@ func main() {
 	defer interrupt_cancel()
 
 	// Start a new stream from the file in a tail mode
-	stream, err := filestream.New(interrupt_ctx, file_name, true)
+	options := []filestream.Option{filestream.WithTail(true)}
+	if *poll_interval > 0 {
+		options = append(options, filestream.WithWatcher(filestream.PollingWatcher), filestream.WithPollInterval(*poll_interval))
+	}
+	if *follow_name {
+		options = append(options, filestream.WithFollowName())
+	}
+	stream, err := filestream.New(interrupt_ctx, file_name, options...)
 	if err != nil {
 		log.Fatalln("Failed to initialize a file stream:", err)
 	}
 	defer stream.Close()
 
-	// Stream content until the stream is terminated
-	buffer := make([]byte, 100)
+	// Skip to the end of the file if asked to
+	if *num_lines > 0 {
+		_, err = stream.SeekLastLines(*num_lines)
+	} else if *num_bytes > 0 {
+		_, err = stream.SeekLastBytes(*num_bytes)
+	}
+	if err != nil {
+		log.Fatalln("Failed to find the end of the file:", err)
+	}
+
+	// Stream content line by line until the stream is terminated
+	lines := filestream.NewLineReader(stream, filestream.LineOptions{})
+	defer lines.Close()
 	for {
-		read_bytes, err := stream.Read(buffer)
+		line, err := lines.ReadLine()
 		if err == io.EOF {
 			break
 		}
@@ -45,6 +70,6 @@ func main() {
 			log.Println("The stream has been stopped")
 			break
 		}
-		os.Stdout.Write(buffer[:read_bytes])
+		os.Stdout.Write(line)
 	}
 }
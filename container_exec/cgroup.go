@@ -0,0 +1,432 @@
+package container_exec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Controllers we need enabled for command cgroups
+var cgroupControllers = []string{"cpu", "memory", "io", "pids"}
+
+// Cgroup within the root holding the container inits, so that they don't count towards the limits of the commands they run
+const initCgroupName = "init"
+
+// How long we wait for a cgroup to become empty before giving up on removing it
+const cgroupRemoveTimeout = 5 * time.Second
+
+// Limits describes resource limits applied to each command via cgroup v2 controllers.
+// Zero values mean there is no limit.
+type Limits struct {
+	MemoryBytes   int64    // memory.max
+	CPUQuotaUsec  int64    // cpu.max quota: CPU time the command can use within each period
+	CPUPeriodUsec int64    // cpu.max period
+	IODevices     []string // Block devices ("major:minor") the IO limits are applied to
+	IOReadBPS     int64    // io.max rbps
+	IOWriteBPS    int64    // io.max wbps
+	IOReadIOPS    int64    // io.max riops
+	IOWriteIOPS   int64    // io.max wiops
+	PidsMax       int64    // pids.max
+}
+
+// ResourceUsage describes resources used by a command, read from the cgroup v2 stats files
+type ResourceUsage struct {
+	PeakMemoryBytes int64 `json:"peak_memory_bytes"` // memory.peak (not available before Linux 5.19)
+	CPUTimeUsec     int64 `json:"cpu_time_usec"`     // cpu.stat usage_usec
+	IOReadBytes     int64 `json:"io_read_bytes"`     // io.stat rbytes, summed over all devices
+	IOWriteBytes    int64 `json:"io_write_bytes"`    // io.stat wbytes, summed over all devices
+	OOMKilled       bool  `json:"oom_killed"`        // memory.events oom_kill is not zero
+}
+
+// ResourceStats is a snapshot of the resources used by a running command, read from the cgroup v2 stats files
+type ResourceStats struct {
+	MemoryBytes  int64 // memory.current
+	CPUTimeUsec  int64 // cpu.stat usage_usec
+	IOReadBytes  int64 // io.stat rbytes, summed over all devices
+	IOWriteBytes int64 // io.stat wbytes, summed over all devices
+	Pids         int64 // pids.current (the container init is kept in a separate cgroup, so it is not included)
+}
+
+// DefaultLimits is a conservative set of limits used unless configured otherwise
+var DefaultLimits = Limits{
+	MemoryBytes:   256 * 1024 * 1024,
+	CPUQuotaUsec:  50000,
+	CPUPeriodUsec: 100000,
+	IOReadBPS:     10 * 1024 * 1024,
+	IOWriteBPS:    10 * 1024 * 1024,
+	PidsMax:       256,
+}
+
+// DefaultMaxLimits is a set of ceilings for limits requested by clients used unless configured otherwise
+var DefaultMaxLimits = Limits{
+	MemoryBytes:   2 * 1024 * 1024 * 1024,
+	CPUQuotaUsec:  200000,
+	CPUPeriodUsec: 100000,
+	IOReadBPS:     100 * 1024 * 1024,
+	IOWriteBPS:    100 * 1024 * 1024,
+	PidsMax:       1024,
+}
+
+// Valid range for the CPU period according to the kernel
+const (
+	minCPUPeriodUsec = 1000
+	maxCPUPeriodUsec = 1000000
+)
+
+// ErrInvalidLimits is returned when requested limits are invalid or exceed the configured maximum
+var ErrInvalidLimits = errors.New("invalid limits")
+
+// Validate makes sure the limits are valid and do not exceed given ceilings (zero ceilings mean no maximum)
+func (l Limits) Validate(max Limits) error {
+	checks := []struct {
+		name  string
+		value int64
+		max   int64
+	}{
+		{"memory", l.MemoryBytes, max.MemoryBytes},
+		{"IO read bps", l.IOReadBPS, max.IOReadBPS},
+		{"IO write bps", l.IOWriteBPS, max.IOWriteBPS},
+		{"IO read iops", l.IOReadIOPS, max.IOReadIOPS},
+		{"IO write iops", l.IOWriteIOPS, max.IOWriteIOPS},
+		{"pids", l.PidsMax, max.PidsMax},
+	}
+	for _, check := range checks {
+		if check.value < 0 {
+			return fmt.Errorf("%w: %s limit can't be negative", ErrInvalidLimits, check.name)
+		}
+		// Zero means "no limit", which is only allowed when there is no maximum
+		if check.max > 0 && (check.value == 0 || check.value > check.max) {
+			return fmt.Errorf("%w: %s limit must be between 1 and %d", ErrInvalidLimits, check.name, check.max)
+		}
+	}
+
+	if l.CPUQuotaUsec < 0 {
+		return fmt.Errorf("%w: CPU quota can't be negative", ErrInvalidLimits)
+	}
+	period := l.cpuPeriod()
+	if period < minCPUPeriodUsec || period > maxCPUPeriodUsec {
+		return fmt.Errorf("%w: CPU period must be between %d and %d", ErrInvalidLimits, minCPUPeriodUsec, maxCPUPeriodUsec)
+	}
+
+	// CPU limits are compared as a share of CPU time, since periods may differ
+	if max.CPUQuotaUsec > 0 {
+		if l.CPUQuotaUsec == 0 || l.CPUQuotaUsec*max.cpuPeriod() > max.CPUQuotaUsec*period {
+			return fmt.Errorf("%w: CPU limit can't exceed %d/%d", ErrInvalidLimits, max.CPUQuotaUsec, max.cpuPeriod())
+		}
+	}
+	return nil
+}
+
+// Returns the CPU period, falling back to the default one if not set
+func (l Limits) cpuPeriod() int64 {
+	if l.CPUPeriodUsec == 0 {
+		return DefaultLimits.CPUPeriodUsec
+	}
+	return l.CPUPeriodUsec
+}
+
+// SetupCgroupRoot creates a cgroup used as a parent for all command cgroups
+// (along with the cgroup for their inits) and enables all the controllers we need for its children
+func SetupCgroupRoot(root string) error {
+	err := os.MkdirAll(path.Join(root, initCgroupName), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create cgroup '%s': %w", root, err)
+	}
+
+	// Controllers need to be enabled on every level of the hierarchy down to our commands
+	for _, dir := range []string{path.Dir(root), root} {
+		err = enableControllers(dir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DetectIODevice returns the "major:minor" number of the whole disk backing a given path
+func DetectIODevice(fileName string) (string, error) {
+	var stat unix.Stat_t
+	err := unix.Stat(fileName, &stat)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat '%s': %w", fileName, err)
+	}
+
+	device := fmt.Sprintf("%d:%d", unix.Major(stat.Dev), unix.Minor(stat.Dev))
+	sysPath := path.Join("/sys/dev/block", device)
+	if _, err := os.Stat(sysPath); err != nil {
+		return "", fmt.Errorf("'%s' is not backed by a block device", fileName)
+	}
+
+	// IO limits can only be applied to whole disks, so we need to find the parent of a partition
+	if _, err := os.Stat(path.Join(sysPath, "partition")); err == nil {
+		devicePath, err := filepath.EvalSymlinks(sysPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve '%s': %w", sysPath, err)
+		}
+		parent, err := os.ReadFile(path.Join(path.Dir(devicePath), "dev"))
+		if err != nil {
+			return "", fmt.Errorf("failed to find the disk for partition %s: %w", device, err)
+		}
+		device = strings.TrimSpace(string(parent))
+	}
+	return device, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+// A cgroup created for a single command
+type cgroup struct {
+	path string
+}
+
+// Creates a new cgroup within a given root and applies limits to it
+func newCgroup(root string, name string, limits Limits) (*cgroup, error) {
+	cg := &cgroup{path: path.Join(root, name)}
+	err := os.Mkdir(cg.path, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cgroup '%s': %w", cg.path, err)
+	}
+
+	err = cg.applyLimits(limits)
+	if err != nil {
+		_ = cg.remove()
+		return nil, err
+	}
+	return cg, nil
+}
+
+// Writes limits into the controller files of the cgroup
+func (cg *cgroup) applyLimits(limits Limits) error {
+	if limits.MemoryBytes > 0 {
+		err := cg.write("memory.max", strconv.FormatInt(limits.MemoryBytes, 10))
+		if err != nil {
+			return err
+		}
+	}
+
+	if limits.CPUQuotaUsec > 0 {
+		err := cg.write("cpu.max", fmt.Sprintf("%d %d", limits.CPUQuotaUsec, limits.cpuPeriod()))
+		if err != nil {
+			return err
+		}
+	}
+
+	if limits.PidsMax > 0 {
+		err := cg.write("pids.max", strconv.FormatInt(limits.PidsMax, 10))
+		if err != nil {
+			return err
+		}
+	}
+
+	ioLimits := ioMaxLimits(limits)
+	if ioLimits != "" {
+		for _, device := range limits.IODevices {
+			err := cg.write("io.max", device+ioLimits)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the resources used by the processes of the cgroup so far
+func (cg *cgroup) usage() (*ResourceUsage, error) {
+	cpuStat, err := cg.readKeyedFile("cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	memoryEvents, err := cg.readKeyedFile("memory.events")
+	if err != nil {
+		return nil, err
+	}
+	ioStat, err := cg.readIOStat()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &ResourceUsage{
+		CPUTimeUsec:  cpuStat["usage_usec"],
+		IOReadBytes:  ioStat["rbytes"],
+		IOWriteBytes: ioStat["wbytes"],
+		OOMKilled:    memoryEvents["oom_kill"] > 0,
+	}
+
+	// Older kernels don't track the peak, so it is left at zero there
+	peak, err := cg.readValue("memory.peak")
+	if err == nil {
+		usage.PeakMemoryBytes = peak
+	}
+	return usage, nil
+}
+
+// Reads the current resource usage of the processes in the cgroup
+func (cg *cgroup) stats() (*ResourceStats, error) {
+	memory, err := cg.readValue("memory.current")
+	if err != nil {
+		return nil, err
+	}
+	pids, err := cg.readValue("pids.current")
+	if err != nil {
+		return nil, err
+	}
+	cpuStat, err := cg.readKeyedFile("cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	ioStat, err := cg.readIOStat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourceStats{
+		MemoryBytes:  memory,
+		CPUTimeUsec:  cpuStat["usage_usec"],
+		IOReadBytes:  ioStat["rbytes"],
+		IOWriteBytes: ioStat["wbytes"],
+		Pids:         pids,
+	}, nil
+}
+
+// Opens the cgroup directory, the descriptor is used to put a new process into the cgroup
+func (cg *cgroup) open() (*os.File, error) {
+	return openCgroup(cg.path)
+}
+
+// Opens the cgroup the init of the command is put into (see SetupCgroupRoot)
+func (cg *cgroup) openInit() (*os.File, error) {
+	return openCgroup(path.Join(path.Dir(cg.path), initCgroupName))
+}
+
+// Kills any processes left in the cgroup and removes it
+func (cg *cgroup) remove() error {
+	// Available since Linux 5.14, on older kernels we rely on the process group kill
+	_ = cg.write("cgroup.kill", "1")
+
+	// The cgroup can't be removed until all of its processes are gone, which may take a moment
+	deadline := time.Now().Add(cgroupRemoveTimeout)
+	for {
+		err := os.Remove(cg.path)
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if !errors.Is(err, unix.EBUSY) || time.Now().After(deadline) {
+			return fmt.Errorf("failed to remove cgroup '%s': %w", cg.path, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Writes a value into a given cgroup control file
+func (cg *cgroup) write(fileName string, value string) error {
+	filePath := path.Join(cg.path, fileName)
+	err := os.WriteFile(filePath, []byte(value), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write '%s' into '%s': %w", value, filePath, err)
+	}
+	return nil
+}
+
+// Reads a cgroup file holding a single number (e.g. memory.current)
+func (cg *cgroup) readValue(fileName string) (int64, error) {
+	content, err := os.ReadFile(path.Join(cg.path, fileName))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cgroup stats: %w", err)
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse cgroup stats '%s': %w", fileName, err)
+	}
+	return value, nil
+}
+
+// Reads a cgroup file with a "key value" pair on each line (e.g. cpu.stat)
+func (cg *cgroup) readKeyedFile(fileName string) (map[string]int64, error) {
+	content, err := os.ReadFile(path.Join(cg.path, fileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cgroup stats: %w", err)
+	}
+
+	values := make(map[string]int64)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			values[fields[0]], _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+	return values, nil
+}
+
+// Reads io.stat, summing the "key=value" counters of all devices
+func (cg *cgroup) readIOStat() (map[string]int64, error) {
+	content, err := os.ReadFile(path.Join(cg.path, "io.stat"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cgroup stats: %w", err)
+	}
+
+	totals := make(map[string]int64)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, field := range fields[1:] { // The first field is the device number
+			key, value, found := strings.Cut(field, "=")
+			if found {
+				parsed, _ := strconv.ParseInt(value, 10, 64)
+				totals[key] += parsed
+			}
+		}
+	}
+	return totals, nil
+}
+
+// Returns the io.max limits line (without the device number) or an empty string if there are no IO limits
+func ioMaxLimits(limits Limits) string {
+	line := ""
+	for _, limit := range []struct {
+		key   string
+		value int64
+	}{
+		{"rbps", limits.IOReadBPS},
+		{"wbps", limits.IOWriteBPS},
+		{"riops", limits.IOReadIOPS},
+		{"wiops", limits.IOWriteIOPS},
+	} {
+		if limit.value > 0 {
+			line += fmt.Sprintf(" %s=%d", limit.key, limit.value)
+		}
+	}
+	return line
+}
+
+// Opens a given cgroup directory
+func openCgroup(dirName string) (*os.File, error) {
+	dir, err := os.Open(dirName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cgroup '%s': %w", dirName, err)
+	}
+	return dir, nil
+}
+
+// Enables all the controllers we need for the children of a given cgroup
+func enableControllers(dir string) error {
+	value := ""
+	for _, controller := range cgroupControllers {
+		value += " +" + controller
+	}
+
+	fileName := path.Join(dir, "cgroup.subtree_control")
+	err := os.WriteFile(fileName, []byte(strings.TrimSpace(value)), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to enable cgroup controllers in '%s': %w", fileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package container_exec
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Returns true if the host has a cgroup v2 hierarchy with all the controllers we need
+func cgroupsAvailable() bool {
+	controllers, err := os.ReadFile("/sys/fs/cgroup/cgroup.controllers")
+	if err != nil || os.Geteuid() != 0 {
+		return false
+	}
+
+	available := strings.Fields(string(controllers))
+	for _, controller := range cgroupControllers {
+		found := false
+		for _, c := range available {
+			found = found || c == controller
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Reads a cgroup control file
+func readCgroupFile(dir string, fileName string) string {
+	content, _ := os.ReadFile(path.Join(dir, fileName))
+	return strings.TrimSpace(string(content))
+}
+
+func TestCgroup(t *testing.T) {
+	Convey("newCgroup()", t, func() {
+		// A plain directory is enough to check what we write into the control files
+		root, _ := os.MkdirTemp("", "cgroup_test")
+
+		Convey("Should write all configured limits", func() {
+			cg, err := newCgroup(root, "cmd", Limits{
+				MemoryBytes:   1024 * 1024,
+				CPUQuotaUsec:  20000,
+				CPUPeriodUsec: 100000,
+				IODevices:     []string{"8:0"},
+				IOReadBPS:     1000,
+				IOWriteIOPS:   10,
+				PidsMax:       16,
+			})
+			So(err, ShouldBeNil)
+			So(cg.path, ShouldEqual, path.Join(root, "cmd"))
+
+			So(readCgroupFile(cg.path, "memory.max"), ShouldEqual, "1048576")
+			So(readCgroupFile(cg.path, "cpu.max"), ShouldEqual, "20000 100000")
+			So(readCgroupFile(cg.path, "io.max"), ShouldEqual, "8:0 rbps=1000 wiops=10")
+			So(readCgroupFile(cg.path, "pids.max"), ShouldEqual, "16")
+		})
+
+		Convey("Should not write anything for zero limits", func() {
+			cg, err := newCgroup(root, "cmd", Limits{IODevices: []string{"8:0"}})
+			So(err, ShouldBeNil)
+
+			entries, _ := os.ReadDir(cg.path)
+			So(entries, ShouldBeEmpty)
+		})
+
+		Convey("Should read the resource usage from the stats files", func() {
+			cg, _ := newCgroup(root, "cmd", Limits{})
+			os.WriteFile(path.Join(cg.path, "cpu.stat"), []byte("usage_usec 1500\nuser_usec 1000\nsystem_usec 500\n"), 0644)
+			os.WriteFile(path.Join(cg.path, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"), 0644)
+			os.WriteFile(path.Join(cg.path, "io.stat"), []byte("8:0 rbytes=100 wbytes=200 rios=1 wios=2\n8:16 rbytes=10 wbytes=20 rios=1 wios=1\n"), 0644)
+			os.WriteFile(path.Join(cg.path, "memory.peak"), []byte("4096\n"), 0644)
+
+			usage, err := cg.usage()
+			So(err, ShouldBeNil)
+			So(*usage, ShouldResemble, ResourceUsage{
+				PeakMemoryBytes: 4096,
+				CPUTimeUsec:     1500,
+				IOReadBytes:     110,
+				IOWriteBytes:    220,
+				OOMKilled:       true,
+			})
+
+			Convey("Should leave the peak memory at zero if it is not tracked", func() {
+				os.Remove(path.Join(cg.path, "memory.peak"))
+				usage, err := cg.usage()
+				So(err, ShouldBeNil)
+				So(usage.PeakMemoryBytes, ShouldEqual, 0)
+			})
+		})
+
+		Convey("Should read the current resource stats", func() {
+			cg, _ := newCgroup(root, "cmd", Limits{})
+			os.WriteFile(path.Join(cg.path, "memory.current"), []byte("8192\n"), 0644)
+			os.WriteFile(path.Join(cg.path, "pids.current"), []byte("3\n"), 0644)
+			os.WriteFile(path.Join(cg.path, "cpu.stat"), []byte("usage_usec 1500\n"), 0644)
+			os.WriteFile(path.Join(cg.path, "io.stat"), []byte("8:0 rbytes=100 wbytes=200\n"), 0644)
+
+			stats, err := cg.stats()
+			So(err, ShouldBeNil)
+			So(*stats, ShouldResemble, ResourceStats{MemoryBytes: 8192, CPUTimeUsec: 1500, IOReadBytes: 100, IOWriteBytes: 200, Pids: 3})
+
+			os.Remove(path.Join(cg.path, "pids.current"))
+			_, err = cg.stats()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Should fail if the cgroup already exists", func() {
+			_, err := newCgroup(root, "cmd", Limits{})
+			So(err, ShouldBeNil)
+			_, err = newCgroup(root, "cmd", Limits{})
+			So(err, ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			os.RemoveAll(root)
+		})
+	})
+
+	Convey("Limits.Validate()", t, func() {
+		Convey("Should accept the default limits", func() {
+			So(DefaultLimits.Validate(DefaultMaxLimits), ShouldBeNil)
+		})
+
+		Convey("Should accept anything when there is no maximum", func() {
+			So(Limits{}.Validate(Limits{}), ShouldBeNil)
+			So(Limits{MemoryBytes: 1 << 40}.Validate(Limits{}), ShouldBeNil)
+		})
+
+		Convey("Should reject negative limits", func() {
+			So(Limits{PidsMax: -1}.Validate(Limits{}), ShouldWrap, ErrInvalidLimits)
+			So(Limits{CPUQuotaUsec: -1}.Validate(Limits{}), ShouldWrap, ErrInvalidLimits)
+		})
+
+		Convey("Should reject limits exceeding the maximum", func() {
+			max := Limits{MemoryBytes: 1000, PidsMax: 10}
+			So(Limits{MemoryBytes: 1000, PidsMax: 10}.Validate(max), ShouldBeNil)
+			So(Limits{MemoryBytes: 1001, PidsMax: 10}.Validate(max), ShouldWrap, ErrInvalidLimits)
+			So(Limits{MemoryBytes: 1000, PidsMax: 11}.Validate(max), ShouldWrap, ErrInvalidLimits)
+		})
+
+		Convey("Should not allow unlimited values when there is a maximum", func() {
+			So(Limits{PidsMax: 0}.Validate(Limits{PidsMax: 10}), ShouldWrap, ErrInvalidLimits)
+		})
+
+		Convey("Should compare CPU limits as a share of CPU time", func() {
+			max := Limits{CPUQuotaUsec: 100000, CPUPeriodUsec: 100000}
+			So(Limits{CPUQuotaUsec: 200000, CPUPeriodUsec: 200000}.Validate(max), ShouldBeNil)
+			So(Limits{CPUQuotaUsec: 200000, CPUPeriodUsec: 100000}.Validate(max), ShouldWrap, ErrInvalidLimits)
+		})
+
+		Convey("Should reject CPU periods outside of the kernel range", func() {
+			So(Limits{CPUPeriodUsec: 10}.Validate(Limits{}), ShouldWrap, ErrInvalidLimits)
+			So(Limits{CPUPeriodUsec: 10000000}.Validate(Limits{}), ShouldWrap, ErrInvalidLimits)
+		})
+	})
+
+	Convey("Running commands in cgroups", t, func() {
+		if !cgroupsAvailable() {
+			SkipConvey("cgroup v2 with cpu, memory and io controllers is not available", func() {})
+			return
+		}
+
+		logsDir, _ := os.MkdirTemp("", "cgroup_test_logs")
+		root := "/sys/fs/cgroup/teleport-exec-test"
+		pm, err := NewProcessManager(Config{
+			LogsDir:    logsDir,
+			CgroupRoot: root,
+			Limits:     Limits{MemoryBytes: 64 * 1024 * 1024, CPUQuotaUsec: 10000, CPUPeriodUsec: 100000},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("Should start commands within a dedicated cgroup with limits applied", func() {
+			cmd, err := pm.StartCommand([]string{"sh", "-c", "cat /proc/self/cgroup; cat /sys/fs/cgroup/teleport-exec-test/$(basename $(cut -d: -f3 /proc/self/cgroup))/memory.max"}, CommandOptions{Owner: "alice"})
+			So(err, ShouldBeNil)
+			cmd.Wait()
+
+			output := commandOutput(cmd)
+			So(output, ShouldContainSubstring, "/teleport-exec-test/"+cmd.Id)
+			So(output, ShouldContainSubstring, "67108864")
+		})
+
+		Convey("Should remove the cgroup after the command is finished", func() {
+			cmd, err := pm.StartCommand([]string{"true"}, CommandOptions{Owner: "alice"})
+			So(err, ShouldBeNil)
+			cmd.Wait()
+
+			_, err = os.Stat(path.Join(root, cmd.Id))
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("Should report the resource stats of running commands", func() {
+			cmd, err := pm.StartCommand([]string{"sleep", "100"}, CommandOptions{Owner: "alice"})
+			So(err, ShouldBeNil)
+			defer cmd.Wait()
+			defer cmd.Kill()
+
+			// Only sleep itself, the container init is kept out of the command cgroup
+			stats, err := cmd.Stats()
+			So(err, ShouldBeNil)
+			So(stats.Pids, ShouldEqual, 1)
+			So(stats.MemoryBytes, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("Should only count the processes of the command towards its limits", func() {
+			limits := Limits{MemoryBytes: 64 * 1024 * 1024, CPUQuotaUsec: 10000, CPUPeriodUsec: 100000, PidsMax: 1}
+			cmd, err := pm.StartCommand([]string{"echo", "hello"}, CommandOptions{Owner: "alice", Limits: &limits})
+			So(err, ShouldBeNil)
+			cmd.Wait()
+
+			So(commandOutput(cmd), ShouldEqual, "hello\n")
+			So(cmd.ResultCode(), ShouldEqual, 0)
+		})
+
+		Convey("Should collect the resource usage of finished commands", func() {
+			cmd, err := pm.StartCommand([]string{"sh", "-c", "i=0; while [ $i -lt 10000 ]; do i=$((i+1)); done"}, CommandOptions{Owner: "alice"})
+			So(err, ShouldBeNil)
+			cmd.Wait()
+
+			So(cmd.Usage(), ShouldNotBeNil)
+			So(cmd.Usage().CPUTimeUsec, ShouldBeGreaterThan, 0)
+			So(cmd.Usage().OOMKilled, ShouldBeFalse)
+		})
+
+		Reset(func() {
+			os.RemoveAll(logsDir)
+			os.Remove(path.Join(root, initCgroupName))
+			os.Remove(root)
+		})
+	})
+}
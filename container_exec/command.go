@@ -0,0 +1,630 @@
+package container_exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"teleport-exec/filestream"
+	"teleport-exec/logframe"
+
+	"golang.org/x/sys/unix"
+)
+
+// ErrStatsUnavailable is returned when asking for resource stats of a command which is not running or not using cgroups
+var ErrStatsUnavailable = errors.New("resource stats are not available")
+
+// ErrCommandRunning is returned when trying to remove a command which is still running
+var ErrCommandRunning = errors.New("command is running")
+
+// Amount of the most recent output of a running command kept in memory for its log streams
+const logBufferSize = 256 * 1024
+
+type Command struct {
+	Id          string   // Unique command id (a UUID string)
+	Command     []string // Command and its arguments
+	Owner       string   // Name of the client who started the command
+	WorkingDir  string   // Working directory of the command (the current directory if empty)
+	Env         []string // Extra environment variables ("KEY=VALUE") added to the current environment
+	LogFileName string   // Base name of the segments holding stdout+stderr output of the command (see logframe for the format)
+
+	cmd            *exec.Cmd
+	logFile        *filestream.SegmentWriter
+	output         *filestream.Broadcaster // Passes new output to log streams of a running process (nil once finished)
+	logSegmentSize int64                   // Size at which the log is rotated into a new segment (never rotated if 0)
+	maxLogSize     int64                   // Maximum size of the log, the oldest segments are removed beyond that (unlimited if 0)
+	cgroup         *cgroup                 // Cgroup limiting command resources (nil if cgroups are not used)
+	exitStatus     *os.File                // Read end of the pipe the init reports the command wait status through
+	stdin          []byte                  // Data fed into the command stdin (stdin is empty if nil)
+	store          *Store                  // Store persisting the command state (the state is not persisted if nil)
+
+	mu         sync.RWMutex   // Protects access to the status fields below
+	started    bool           // Set to true when the process has been successfully started
+	running    bool           // Set to true while the process is running
+	exited     bool           // Set to true if the process has exited normally (was not killed by a signal)
+	lost       bool           // Set to true if the server has stopped while the process was running
+	abandoned  bool           // Set to true if the process has been killed right after starting, so its state is not persisted
+	resultCode int32          // Process exit code (-1 if the process has been killed by a signal)
+	signal     syscall.Signal // Signal which has killed the process (0 if the process has exited)
+	startTime  time.Time      // Time the process was started
+	endTime    time.Time      // Time the process finished (zero while running)
+	pid        int            // Host PID of the container init running the command
+	usage      *ResourceUsage // Resources used by the process, read from its cgroup when finished (nil if not available)
+	finished   chan bool      // Closed when the process has finished and its status is available
+}
+
+// NewCommand creates a new Command instance that will log its output into a given directory
+func NewCommand(id string, command []string, logsDir string) *Command {
+	return &Command{
+		Id:          id,
+		Command:     command,
+		LogFileName: path.Join(logsDir, id+".log"),
+		resultCode:  -1,
+		finished:    make(chan bool),
+	}
+}
+
+// restoreCommand creates a Command instance for a command loaded from a store.
+// Commands which were running when the state was saved are marked as lost.
+func restoreCommand(record CommandRecord, store *Store) *Command {
+	c := &Command{
+		Id:          record.Id,
+		Command:     record.Command,
+		Owner:       record.Owner,
+		LogFileName: record.LogFileName,
+		store:       store,
+		started:     true,
+		exited:      record.Exited,
+		lost:        record.Lost || record.Running,
+		resultCode:  record.ResultCode,
+		signal:      record.Signal,
+		pid:         record.Pid,
+		usage:       record.Usage,
+		startTime:   record.StartTime,
+		endTime:     record.EndTime,
+		finished:    make(chan bool),
+	}
+	if record.Running {
+		c.resultCode = -1
+	}
+	close(c.finished)
+	return c
+}
+
+// Kills the processes of a command left behind by a previous server, which was stopped while the command was running,
+// and removes its cgroup. Killing the init takes down everything in the PID namespace of the command.
+func killLeftovers(record CommandRecord) error {
+	// The PID could have been reused since, so we make sure it still belongs to an init
+	if record.Pid > 0 && isInitProcess(record.Pid) {
+		err := syscall.Kill(-record.Pid, syscall.SIGKILL)
+		if err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("failed to kill the leftover processes of command %s: %w", record.Id, err)
+		}
+	}
+
+	if record.CgroupPath != "" {
+		return (&cgroup{path: record.CgroupPath}).remove()
+	}
+	return nil
+}
+
+// Start starts the process and a goroutine waiting for it to finish
+func (c *Command) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.started {
+		return fmt.Errorf("command %s has already been started", c.Id)
+	}
+
+	err := c.startProcess()
+	if err != nil {
+		// The cgroup is not going to be used by anybody else
+		if c.cgroup != nil {
+			_ = c.cgroup.remove()
+		}
+		return err
+	}
+
+	c.started = true
+	c.running = true
+	c.startTime = time.Now()
+	go c.waitForProcess()
+
+	// A command we can't keep track of after a restart should not be running. It has never been reported
+	// to anybody, so its state should not be saved when it finishes either.
+	err = c.save()
+	if err != nil {
+		c.abandoned = true
+		_ = syscall.Kill(-c.cmd.Process.Pid, syscall.SIGKILL)
+		return err
+	}
+	return nil
+}
+
+// Running returns true if the process is still running
+func (c *Command) Running() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.running
+}
+
+// Exited returns true if the process has finished normally (was not killed by a signal)
+func (c *Command) Exited() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.exited
+}
+
+// Lost returns true if the server has stopped while the process was running, so its result is unknown
+func (c *Command) Lost() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lost
+}
+
+// StartTime returns the time the process was started
+func (c *Command) StartTime() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.startTime
+}
+
+// EndTime returns the time the process finished (zero if still running or lost)
+func (c *Command) EndTime() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.endTime
+}
+
+// Signal returns the signal which has killed the process (0 if still running or exited normally)
+func (c *Command) Signal() syscall.Signal {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.signal
+}
+
+// Pid returns the host PID of the container init running the command (0 if it has failed to start)
+func (c *Command) Pid() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pid
+}
+
+// Usage returns the resources used by the finished process (nil if still running or cgroups are not used)
+func (c *Command) Usage() *ResourceUsage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.usage
+}
+
+// ResultCode returns the exit code of the process (-1 if still running or killed by a signal)
+func (c *Command) ResultCode() int32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.resultCode
+}
+
+// Wait blocks until the process has finished
+func (c *Command) Wait() {
+	<-c.finished
+}
+
+// Done returns a channel closed when the process has finished
+func (c *Command) Done() <-chan bool {
+	return c.finished
+}
+
+// Stats returns the current resource usage of a running process (see ErrStatsUnavailable)
+func (c *Command) Stats() (*ResourceStats, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// The cgroup is removed once the process has finished
+	if !c.running {
+		return nil, fmt.Errorf("%w: command %s is not running", ErrStatsUnavailable, c.Id)
+	}
+	if c.cgroup == nil {
+		return nil, fmt.Errorf("%w: resource limits are disabled", ErrStatsUnavailable)
+	}
+	return c.cgroup.stats()
+}
+
+// Kill stops the process and all of its children by killing the whole process group
+func (c *Command) Kill() error {
+	return c.sendSignal(syscall.SIGKILL)
+}
+
+// Stop sends a given signal to the whole process group, giving the process a chance to shut down gracefully.
+// If the process is still running after the grace period, it gets killed (see Kill).
+// Only the signals passed on by the container init can be sent (see ErrInvalidOptions and ParseSignal).
+func (c *Command) Stop(signal syscall.Signal, gracePeriod time.Duration) error {
+	if !isSupportedSignal(signal) {
+		return fmt.Errorf("%w: signal %s can't be sent to commands", ErrInvalidOptions, unix.SignalName(signal))
+	}
+
+	err := c.sendSignal(signal)
+	if err != nil || signal == syscall.SIGKILL {
+		return err
+	}
+
+	go func() {
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+
+		select {
+		case <-c.finished:
+		case <-timer.C:
+			_ = c.sendSignal(syscall.SIGKILL) // The process may have just finished, so errors are expected
+		}
+	}()
+	return nil
+}
+
+// ParseSignal returns a signal with a given name, with or without the SIG prefix (e.g. "TERM" or "SIGTERM").
+// Only KILL, TERM, INT, HUP, QUIT, USR1 and USR2 are accepted, since other signals don't reach the command.
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+
+	signal := unix.SignalNum(name)
+	if signal == 0 {
+		return 0, fmt.Errorf("%w: unknown signal '%s'", ErrInvalidOptions, name)
+	}
+	if !isSupportedSignal(signal) {
+		return 0, fmt.Errorf("%w: signal '%s' can't be sent to commands", ErrInvalidOptions, name)
+	}
+	return signal, nil
+}
+
+// NewLogStream returns a new stream for reading the command output. By default it starts from the very beginning
+// of the retained output, but could also start from a given offset or the last lines/bytes of the output (see LogStreamOptions).
+// Offsets removed by log rotation are replaced by the beginning of the retained output.
+// When tailing, the stream will be following the log until the command is finished.
+// Streams of a running command receive recent output from memory and only read the log for older output.
+func (c *Command) NewLogStream(ctx context.Context, options LogStreamOptions) (*LogStream, error) {
+	err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	position, err := c.logPosition(options)
+	if err != nil {
+		return nil, err
+	}
+
+	openLog := func() (*filestream.FileStream, error) {
+		return filestream.New(ctx, c.LogFileName, filestream.WithSegments())
+	}
+
+	if c.output != nil {
+		reader := c.output.NewReader(ctx, position.Offset, options.Tail, openLog)
+		return newLogStream(reader, position, options), nil
+	}
+
+	file, err := openLog()
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := file.Seek(position.Offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek log file '%s': %w", c.LogFileName, err)
+	}
+
+	// Rotation may have removed the data since we've found the position, then we start from the oldest retained frame
+	if offset != position.Offset {
+		position = logframe.Position{Offset: offset}
+		options.Skip = nil
+	}
+	return newLogStream(file, position, options), nil
+}
+
+// CloseLogStream closes a given stream
+func (c *Command) CloseLogStream(stream *LogStream) error {
+	return stream.close()
+}
+
+//-------------------------------------------------------------------------------------------------
+// Sends a signal to the process and all of its children
+func (c *Command) sendSignal(signal syscall.Signal) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.running {
+		return fmt.Errorf("command %s is not running", c.Id)
+	}
+
+	// The process group may already be gone if the process has just finished
+	err := syscall.Kill(-c.cmd.Process.Pid, signal)
+	if err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("failed to send %s to command %s: %w", unix.SignalName(signal), c.Id, err)
+	}
+	return nil
+}
+
+// Creates the log file and starts the process in new namespaces within the command cgroup
+func (c *Command) startProcess() error {
+	if len(c.Command) == 0 {
+		return errors.New("empty command")
+	}
+
+	// Resolve the binary here, so that we could report missing commands right away.
+	// The command uses the host root filesystem, so the path is going to be the same within the container.
+	binary, err := c.lookPath(c.Command[0])
+	if err != nil {
+		return fmt.Errorf("failed to find command %v: %w", c.Command, err)
+	}
+
+	logFile, err := filestream.NewSegmentWriter(c.LogFileName, c.logSegmentSize, c.maxLogSize)
+	if err != nil {
+		return fmt.Errorf("failed to create a log file '%s': %w", c.LogFileName, err)
+	}
+
+	// The init reports the wait status of the command through a pipe (see runInit)
+	exitStatus, exitStatusWriter, err := os.Pipe()
+	if err != nil {
+		logFile.Close()
+		return fmt.Errorf("failed to create a pipe: %w", err)
+	}
+	defer exitStatusWriter.Close()
+
+	// Re-execute ourselves as a container init (see Init), which then runs the command.
+	// Stdout and stderr go through pipes, so that we could tag the output with the stream it came from.
+	output := filestream.NewBroadcaster(logFile, logBufferSize)
+	logWriter := logframe.NewWriter(output)
+	cmd := &exec.Cmd{
+		Path:       "/proc/self/exe",
+		Args:       initArgs(binary, c.Command),
+		Dir:        c.WorkingDir,
+		Stdout:     logWriter.StreamWriter(logframe.Stdout),
+		Stderr:     logWriter.StreamWriter(logframe.Stderr),
+		ExtraFiles: []*os.File{exitStatusWriter}, // Becomes exitStatusFd
+		SysProcAttr: &syscall.SysProcAttr{
+			Cloneflags: cloneFlags,
+			Setpgid:    true, // Put all processes into a separate process group, so we could kill them all at once
+		},
+	}
+
+	// Put the init into the shared init cgroup and pass it the command cgroup, so that the command is put there
+	// right away (limits are applied before it starts running) and the init does not count towards them
+	if c.cgroup != nil {
+		initCgroupDir, err := c.cgroup.openInit()
+		if err != nil {
+			exitStatus.Close()
+			logFile.Close()
+			return err
+		}
+		defer initCgroupDir.Close()
+
+		cgroupDir, err := c.cgroup.open()
+		if err != nil {
+			exitStatus.Close()
+			logFile.Close()
+			return err
+		}
+		defer cgroupDir.Close()
+
+		cmd.ExtraFiles = append(cmd.ExtraFiles, cgroupDir) // Becomes cgroupFd
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = int(initCgroupDir.Fd())
+	}
+
+	if c.Env != nil {
+		cmd.Env = append(os.Environ(), c.Env...) // Later values take precedence over the current environment
+	}
+	if c.stdin != nil {
+		cmd.Stdin = bytes.NewReader(c.stdin)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		exitStatus.Close()
+		logFile.Close()
+		return fmt.Errorf("failed to start command %v: %w", c.Command, err)
+	}
+
+	c.cmd = cmd
+	c.exitStatus = exitStatus
+	c.pid = cmd.Process.Pid
+	c.logFile = logFile
+	c.output = output
+	return nil
+}
+
+// Returns the full path of a command binary, searching the PATH from the command environment if it is set there.
+// Relative paths are resolved from the command working directory.
+func (c *Command) lookPath(name string) (string, error) {
+	resolve := func(name string) string {
+		if !path.IsAbs(name) && c.WorkingDir != "" {
+			return path.Join(c.WorkingDir, name)
+		}
+		return name
+	}
+
+	if strings.Contains(name, "/") {
+		return exec.LookPath(resolve(name))
+	}
+
+	// Later values take precedence, same as for the command environment itself
+	searchPath, found := "", false
+	for _, variable := range c.Env {
+		if strings.HasPrefix(variable, "PATH=") {
+			searchPath, found = strings.TrimPrefix(variable, "PATH="), true
+		}
+	}
+	if !found {
+		return exec.LookPath(name)
+	}
+
+	for _, dir := range filepath.SplitList(searchPath) {
+		if dir == "" {
+			dir = "." // An empty entry means the current directory
+		}
+		binary, err := exec.LookPath(resolve(dir + "/" + name)) // Keeps the slash, so that LookPath does not search the server PATH
+		if err == nil {
+			return binary, nil
+		}
+	}
+	return "", fmt.Errorf("executable file not found in %s: %w", searchPath, exec.ErrNotFound)
+}
+
+// Returns the position in the log a new log stream should start from
+func (c *Command) logPosition(options LogStreamOptions) (logframe.Position, error) {
+	segments, err := filestream.OpenSegments(c.LogFileName)
+	if err != nil {
+		return logframe.Position{}, fmt.Errorf("failed to open log file '%s': %w", c.LogFileName, err)
+	}
+	defer segments.Close()
+
+	// Rotation never splits frames, so the retained part of the log is a valid log on its own.
+	// The log of a running command may end with a partially written frame, so we stop at the last complete one.
+	start, end := segments.Start(), segments.End()
+	if c.output != nil && c.output.Size() < end {
+		end = c.output.Size()
+	}
+	log := io.NewSectionReader(segments, start, end-start)
+	retained := func(position logframe.Position, err error) (logframe.Position, error) {
+		position.Offset += start
+		return position, err
+	}
+
+	switch {
+	case options.LastLines > 0:
+		return retained(logframe.LastLines(log, log.Size(), options.LastLines, options.Stream))
+	case options.LastBytes > 0:
+		return retained(logframe.LastBytes(log, log.Size(), options.LastBytes, options.Stream))
+	case options.Offset > end:
+		return logframe.Position{}, fmt.Errorf("%w: offset %d is outside of the log (%d bytes)", ErrInvalidOptions, options.Offset, end)
+	case options.Offset == 0 && !options.Since.IsZero():
+		return retained(logframe.Since(log, log.Size(), options.Since))
+	case options.Offset < start:
+		return logframe.Position{Offset: start}, nil // Already removed by rotation
+	}
+
+	ok, err := logframe.IsFrameStart(log, log.Size(), options.Offset-start)
+	if err != nil {
+		return logframe.Position{}, fmt.Errorf("failed to read log file '%s': %w", c.LogFileName, err)
+	}
+	if !ok {
+		return logframe.Position{}, fmt.Errorf("%w: offset %d is not at the start of an output block", ErrInvalidOptions, options.Offset)
+	}
+	return logframe.Position{Offset: options.Offset}, nil
+}
+
+// Waits for the process to finish, updates the command status and notifies all active log streams
+func (c *Command) waitForProcess() {
+	// Errors here are reflected in the process state, so we only care about the state.
+	// Wait also makes sure all the output has been copied into the log.
+	_ = c.cmd.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The init reports how the command has finished, unless the init itself has failed or has been killed
+	status, ok := readExitStatus(c.exitStatus)
+	if !ok {
+		status = c.cmd.ProcessState.Sys().(syscall.WaitStatus)
+	}
+	_ = c.exitStatus.Close()
+
+	c.running = false
+	c.exited = status.Exited()
+	c.resultCode = int32(status.ExitStatus())
+	if status.Signaled() {
+		c.signal = status.Signal()
+	}
+	c.endTime = time.Now()
+
+	// The process is gone, so nothing else is going to be written into the log
+	_ = c.logFile.Close()
+
+	// Collect the final stats, then kill anything left behind in the cgroup and remove it.
+	// There is nobody to report errors to here, so the usage is simply not available if it fails.
+	if c.cgroup != nil {
+		c.usage, _ = c.cgroup.usage()
+		_ = c.cgroup.remove()
+	}
+
+	// Let the readers know there will be no more content in the log, new readers are going to use the log only
+	c.output.Close()
+	c.output = nil
+
+	// Same as above, there is nobody to report errors to. The command would be reported as lost after a restart.
+	_ = c.save()
+
+	close(c.finished)
+}
+
+// Returns the time the process finished, or the time it was started for lost commands. Must be called with the mutex held.
+func (c *Command) finishedTime() time.Time {
+	if c.endTime.IsZero() {
+		return c.startTime
+	}
+	return c.endTime
+}
+
+// Removes the log and the persisted state of a finished command
+func (c *Command) remove() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.running {
+		return fmt.Errorf("%w: command %s has not finished yet", ErrCommandRunning, c.Id)
+	}
+
+	err := filestream.RemoveSegments(c.LogFileName)
+	if err != nil {
+		return fmt.Errorf("failed to remove log file '%s': %w", c.LogFileName, err)
+	}
+
+	if c.store != nil {
+		return c.store.Delete(c.Id)
+	}
+	return nil
+}
+
+// Persists the current command state in the store (if any). Must be called with the mutex held.
+func (c *Command) save() error {
+	if c.store == nil || c.abandoned {
+		return nil
+	}
+
+	cgroupPath := ""
+	if c.cgroup != nil {
+		cgroupPath = c.cgroup.path
+	}
+
+	return c.store.Save(CommandRecord{
+		Id:          c.Id,
+		Command:     c.Command,
+		Owner:       c.Owner,
+		LogFileName: c.LogFileName,
+		StartTime:   c.startTime,
+		EndTime:     c.endTime,
+		Running:     c.running,
+		Exited:      c.exited,
+		ResultCode:  c.resultCode,
+		Signal:      c.signal,
+		Pid:         c.pid,
+		CgroupPath:  cgroupPath,
+		Usage:       c.usage,
+		Lost:        c.lost,
+	})
+}
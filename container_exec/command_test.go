@@ -0,0 +1,677 @@
+package container_exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"teleport-exec/filestream"
+	"teleport-exec/logframe"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Reads a log, returning filestream.ErrDataRemoved at a given offset and skipping to a later one after that
+type removingReader struct {
+	log      []byte
+	offset   int64 // Offset of the next byte to read
+	removeAt int64 // Offset where the data is removed (no more removals if negative)
+	skipTo   int64 // Offset the reader skips to after the removal
+}
+
+func (r *removingReader) Read(data []byte) (int, error) {
+	if r.offset == r.removeAt {
+		r.offset, r.removeAt = r.skipTo, -1
+		return 0, filestream.ErrDataRemoved
+	}
+
+	end := int64(len(r.log))
+	if r.removeAt > r.offset {
+		end = r.removeAt
+	}
+	if r.offset >= end {
+		return 0, io.EOF
+	}
+	n := copy(data, r.log[r.offset:end])
+	r.offset += int64(n)
+	return n, nil
+}
+
+func (r *removingReader) Offset() int64 {
+	return r.offset
+}
+
+func (r *removingReader) Close() error {
+	return nil
+}
+
+// Reads all frames from a log stream
+func readFrames(stream *LogStream) ([]logframe.Frame, error) {
+	var frames []logframe.Frame
+	for {
+		frame, err := stream.ReadFrame()
+		if err == io.EOF {
+			return frames, nil
+		}
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, frame)
+	}
+}
+
+// Returns the frames with the capture times cleared, so that they could be compared with expected ones
+func withoutTimes(frames []logframe.Frame) []logframe.Frame {
+	for i := range frames {
+		frames[i].Time = time.Time{}
+	}
+	return frames
+}
+
+// Reads the whole log stream as combined stdout+stderr output
+func readLogStream(stream *LogStream) (string, error) {
+	frames, err := readFrames(stream)
+	output := ""
+	for _, frame := range frames {
+		output += string(frame.Data)
+	}
+	return output, err
+}
+
+// Returns the combined output a command has written so far
+func commandOutput(cmd *Command) string {
+	stream, err := cmd.NewLogStream(context.Background(), LogStreamOptions{})
+	if err != nil {
+		return ""
+	}
+	defer cmd.CloseLogStream(stream)
+
+	output, _ := readLogStream(stream)
+	return output
+}
+
+func TestCommand(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Command", t, func() {
+		logsDir, _ := os.MkdirTemp("", "container_exec_test")
+
+		Convey("Start()", func() {
+			Convey("Should fail for an empty command", func() {
+				cmd := NewCommand("empty", []string{}, logsDir)
+				So(cmd.Start(), ShouldNotBeNil)
+			})
+
+			Convey("Should fail for a non-existent binary", func() {
+				cmd := NewCommand("missing", []string{"/no/such/binary"}, logsDir)
+				So(cmd.Start(), ShouldNotBeNil)
+				So(cmd.Running(), ShouldBeFalse)
+			})
+
+			Convey("Should fail when called twice", func() {
+				cmd := NewCommand("twice", []string{"true"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				So(cmd.Start(), ShouldNotBeNil)
+				cmd.Wait()
+			})
+		})
+
+		Convey("Should report the result code of a finished command", func() {
+			cmd := NewCommand("exit", []string{"sh", "-c", "exit 42"}, logsDir)
+			So(cmd.Start(), ShouldBeNil)
+			cmd.Wait()
+
+			So(cmd.Running(), ShouldBeFalse)
+			So(cmd.Exited(), ShouldBeTrue)
+			So(cmd.ResultCode(), ShouldEqual, 42)
+		})
+
+		Convey("Should run in a given working directory", func() {
+			cmd := NewCommand("cwd", []string{"pwd"}, logsDir)
+			cmd.WorkingDir = "/etc"
+			So(cmd.Start(), ShouldBeNil)
+			cmd.Wait()
+
+			So(commandOutput(cmd), ShouldEqual, "/etc\n")
+		})
+
+		Convey("Should resolve relative commands from the working directory", func() {
+			os.WriteFile(logsDir+"/script.sh", []byte("#!/bin/sh\necho script\n"), 0700)
+			cmd := NewCommand("script", []string{"./script.sh"}, logsDir)
+			cmd.WorkingDir = logsDir
+			So(cmd.Start(), ShouldBeNil)
+			cmd.Wait()
+
+			So(commandOutput(cmd), ShouldEqual, "script\n")
+		})
+
+		Convey("Should find commands using the PATH from the command environment", func() {
+			binDir := logsDir + "/bin"
+			os.Mkdir(binDir, 0700)
+			os.WriteFile(binDir+"/only-here", []byte("#!/bin/sh\necho found\n"), 0700)
+
+			cmd := NewCommand("path", []string{"only-here"}, logsDir)
+			cmd.Env = []string{"PATH=/nonexistent:" + binDir}
+			So(cmd.Start(), ShouldBeNil)
+			cmd.Wait()
+			So(commandOutput(cmd), ShouldEqual, "found\n")
+
+			Convey("Should not fall back to the server PATH", func() {
+				cmd := NewCommand("path", []string{"true"}, logsDir)
+				cmd.Env = []string{"PATH=" + binDir}
+				So(cmd.Start(), ShouldNotBeNil)
+			})
+		})
+
+		Convey("Should add extra environment variables", func() {
+			cmd := NewCommand("env", []string{"sh", "-c", "echo $FOO-$HOME"}, logsDir)
+			cmd.Env = []string{"FOO=bar", "HOME=/nowhere"}
+			So(cmd.Start(), ShouldBeNil)
+			cmd.Wait()
+
+			So(commandOutput(cmd), ShouldEqual, "bar-/nowhere\n")
+		})
+
+		Convey("Should feed the data into the command stdin", func() {
+			cmd := NewCommand("stdin", []string{"wc", "-l"}, logsDir)
+			cmd.stdin = []byte("one\ntwo\nthree\n")
+			So(cmd.Start(), ShouldBeNil)
+			cmd.Wait()
+
+			So(strings.TrimSpace(commandOutput(cmd)), ShouldEqual, "3")
+		})
+
+		Convey("Kill()", func() {
+			Convey("Should stop a running command", func() {
+				cmd := NewCommand("sleep", []string{"sleep", "100"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				So(cmd.Running(), ShouldBeTrue)
+
+				So(cmd.Kill(), ShouldBeNil)
+				cmd.Wait()
+
+				So(cmd.Running(), ShouldBeFalse)
+				So(cmd.Exited(), ShouldBeFalse)
+				So(cmd.ResultCode(), ShouldEqual, -1)
+				So(cmd.Signal(), ShouldEqual, syscall.SIGKILL)
+				So(cmd.Pid(), ShouldBeGreaterThan, 0)
+				So(cmd.Usage(), ShouldBeNil) // Cgroups are not used
+				_, err := cmd.Stats()
+				So(err, ShouldWrap, ErrStatsUnavailable)
+			})
+
+			Convey("Should return an error for a finished command", func() {
+				cmd := NewCommand("done", []string{"true"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+				So(cmd.Kill(), ShouldNotBeNil)
+			})
+		})
+
+		Convey("Stop()", func() {
+			Convey("Should pass the signal to a command without a handler", func() {
+				cmd := NewCommand("sleep", []string{"sleep", "100"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				time.Sleep(100 * time.Millisecond)
+
+				So(cmd.Stop(syscall.SIGTERM, time.Minute), ShouldBeNil)
+				cmd.Wait()
+
+				So(cmd.Exited(), ShouldBeFalse)
+				So(cmd.Signal(), ShouldEqual, syscall.SIGTERM)
+			})
+
+			Convey("Should let the command handle the signal", func() {
+				cmd := NewCommand("trap", []string{"sh", "-c", "trap 'exit 3' TERM; sleep 100 & wait"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				time.Sleep(100 * time.Millisecond) // Give the shell time to set up the trap
+
+				So(cmd.Stop(syscall.SIGTERM, time.Minute), ShouldBeNil)
+				cmd.Wait()
+
+				So(cmd.Exited(), ShouldBeTrue)
+				So(cmd.ResultCode(), ShouldEqual, 3)
+				So(cmd.Signal(), ShouldEqual, 0)
+			})
+
+			Convey("Should pass other forwarded signals to the command", func() {
+				cmd := NewCommand("usr1", []string{"sh", "-c", "trap 'exit 5' USR1; sleep 100 & wait"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				time.Sleep(100 * time.Millisecond)
+
+				So(cmd.Stop(syscall.SIGUSR1, time.Minute), ShouldBeNil)
+				cmd.Wait()
+
+				So(cmd.Exited(), ShouldBeTrue)
+				So(cmd.ResultCode(), ShouldEqual, 5)
+			})
+
+			Convey("Should reject signals which don't reach the command", func() {
+				cmd := NewCommand("alrm", []string{"sleep", "100"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				defer cmd.Wait()
+				defer cmd.Kill()
+
+				So(cmd.Stop(syscall.SIGALRM, time.Minute), ShouldWrap, ErrInvalidOptions)
+				So(cmd.Running(), ShouldBeTrue)
+			})
+
+			Convey("Should kill the command after the grace period", func() {
+				cmd := NewCommand("ignore", []string{"sh", "-c", "trap '' TERM; sleep 100"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				time.Sleep(100 * time.Millisecond)
+
+				start := time.Now()
+				So(cmd.Stop(syscall.SIGTERM, 200*time.Millisecond), ShouldBeNil)
+				cmd.Wait()
+
+				So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 200*time.Millisecond)
+				So(cmd.Exited(), ShouldBeFalse)
+				So(cmd.Signal(), ShouldEqual, syscall.SIGKILL)
+			})
+		})
+
+		Convey("ParseSignal() should accept names with and without the SIG prefix", func() {
+			signal, err := ParseSignal("TERM")
+			So(err, ShouldBeNil)
+			So(signal, ShouldEqual, syscall.SIGTERM)
+
+			signal, err = ParseSignal("sighup")
+			So(err, ShouldBeNil)
+			So(signal, ShouldEqual, syscall.SIGHUP)
+
+			_, err = ParseSignal("NOPE")
+			So(err, ShouldWrap, ErrInvalidOptions)
+		})
+
+		Convey("ParseSignal() should reject signals which don't reach the command", func() {
+			for _, name := range []string{"ALRM", "WINCH", "STOP", "CONT"} {
+				_, err := ParseSignal(name)
+				So(err, ShouldWrap, ErrInvalidOptions)
+			}
+		})
+
+		Convey("NewLogStream()", func() {
+			Convey("Should return the whole output of a finished command", func() {
+				cmd := NewCommand("echo", []string{"sh", "-c", "echo hello; sleep 0.1; echo world >&2"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{Tail: true})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "hello\nworld\n")
+			})
+
+			Convey("Should tag the output with the stream it came from", func() {
+				cmd := NewCommand("streams", []string{"sh", "-c", "echo out; sleep 0.1; echo err >&2; sleep 0.1; echo out2"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				frames, err := readFrames(stream)
+				So(err, ShouldBeNil)
+				So(withoutTimes(frames), ShouldResemble, []logframe.Frame{
+					{Stream: logframe.Stdout, Data: []byte("out\n")},
+					{Stream: logframe.Stderr, Data: []byte("err\n")},
+					{Stream: logframe.Stdout, Data: []byte("out2\n")},
+				})
+			})
+
+			Convey("Should only return the output captured within a given time range", func() {
+				cmd := NewCommand("times", []string{"sh", "-c", "echo one; sleep 0.3; echo two; sleep 0.3; echo three"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{})
+				So(err, ShouldBeNil)
+				frames, _ := readFrames(stream)
+				cmd.CloseLogStream(stream)
+				So(frames, ShouldHaveLength, 3)
+				So(frames[1].Time.After(frames[0].Time), ShouldBeTrue)
+
+				stream, err = cmd.NewLogStream(ctx, LogStreamOptions{Since: frames[1].Time, Until: frames[1].Time})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "two\n")
+			})
+
+			Convey("Should reject time ranges ending before they start", func() {
+				cmd := NewCommand("range", []string{"true"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				now := time.Now()
+				_, err := cmd.NewLogStream(ctx, LogStreamOptions{Since: now, Until: now.Add(-time.Second)})
+				So(err, ShouldWrap, ErrInvalidOptions)
+			})
+
+			Convey("Should resume reading from the offset of a previous stream", func() {
+				cmd := NewCommand("resume", []string{"sh", "-c", "echo one; sleep 0.1; echo two"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{})
+				So(err, ShouldBeNil)
+				stream.ReadFrame()
+				offset := stream.Offset()
+				cmd.CloseLogStream(stream)
+
+				stream, err = cmd.NewLogStream(ctx, LogStreamOptions{Offset: offset})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "two\n")
+			})
+
+			Convey("Should reject offsets outside of the log", func() {
+				cmd := NewCommand("offset", []string{"echo", "hello"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				_, err := cmd.NewLogStream(ctx, LogStreamOptions{Offset: 1000})
+				So(err, ShouldWrap, ErrInvalidOptions)
+				_, err = cmd.NewLogStream(ctx, LogStreamOptions{Offset: -1})
+				So(err, ShouldWrap, ErrInvalidOptions)
+			})
+
+			Convey("Should reject offsets which are not at the start of an output block", func() {
+				cmd := NewCommand("boundary", []string{"echo", "hello"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				_, err := cmd.NewLogStream(ctx, LogStreamOptions{Offset: 5})
+				So(err, ShouldWrap, ErrInvalidOptions)
+			})
+
+			Convey("Should start from the last lines of the output", func() {
+				cmd := NewCommand("last", []string{"sh", "-c", "echo one; echo two; echo oops >&2; echo three"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{LastLines: 2, Stream: logframe.Stdout})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "two\nthree\n")
+			})
+
+			Convey("Should ignore a partially written frame when starting from the last lines", func() {
+				cmd := NewCommand("partial", []string{"sh", "-c", "echo one; echo two; sleep 5"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				defer cmd.Wait()
+				defer cmd.Kill()
+
+				for commandOutput(cmd) != "one\ntwo\n" {
+					time.Sleep(10 * time.Millisecond)
+				}
+
+				// Make the log look like it is in the middle of writing a frame header
+				file, err := os.OpenFile(filestream.SegmentName(cmd.LogFileName, 0), os.O_WRONLY|os.O_APPEND, 0)
+				So(err, ShouldBeNil)
+				file.Write([]byte{byte(logframe.Stdout), 0, 0, 0, 0})
+				file.Close()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{LastLines: 1})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "two\n")
+			})
+
+			Convey("Should start from the last bytes of the output", func() {
+				cmd := NewCommand("bytes", []string{"echo", "hello world"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{LastBytes: 6})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "world\n")
+			})
+
+			Convey("Should reject more than one starting point", func() {
+				cmd := NewCommand("conflict", []string{"true"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				_, err := cmd.NewLogStream(ctx, LogStreamOptions{LastLines: 1, LastBytes: 1})
+				So(err, ShouldWrap, ErrInvalidOptions)
+			})
+
+			Convey("Should return the output retained after log rotation", func() {
+				cmd := NewCommand("rotate", []string{"sh", "-c", "for i in $(seq 10 59); do echo line $i; sleep 0.01; done"}, logsDir)
+				cmd.logSegmentSize = 100
+				cmd.maxLogSize = 300
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				full := ""
+				for i := 10; i < 60; i++ {
+					full += fmt.Sprintf("line %d\n", i)
+				}
+
+				output := commandOutput(cmd)
+				So(output, ShouldStartWith, "line ")
+				So(len(output), ShouldBeLessThan, len(full))
+				So(strings.HasSuffix(full, output), ShouldBeTrue)
+
+				// Offsets removed by rotation are replaced by the beginning of the retained output
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{Offset: 1})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+				resumed, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(resumed, ShouldEqual, output)
+
+				stream, err = cmd.NewLogStream(ctx, LogStreamOptions{LastLines: 2})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+				last, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(last, ShouldEqual, "line 58\nline 59\n")
+			})
+
+			Convey("Should return whole lines of each stream in the line mode", func() {
+				cmd := NewCommand("lines", []string{"sh", "-c", "printf out; sleep 0.1; echo err >&2; sleep 0.1; echo put; printf last"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{Tail: true, Lines: true})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				frames, err := readFrames(stream)
+				So(err, ShouldBeNil)
+				So(withoutTimes(frames), ShouldResemble, []logframe.Frame{
+					{Stream: logframe.Stderr, Data: []byte("err\n")},
+					{Stream: logframe.Stdout, Data: []byte("output\n")},
+					{Stream: logframe.Stdout, Data: []byte("last")},
+				})
+			})
+
+			Convey("Should resume exactly where the previous stream has stopped in the line mode", func() {
+				cmd := NewCommand("resume-lines", []string{"sh", "-c", "printf one; sleep 0.1; echo err >&2; sleep 0.1; echo two"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{Lines: true})
+				So(err, ShouldBeNil)
+				frame, _ := stream.ReadFrame()
+				So(string(frame.Data), ShouldEqual, "err\n")
+				offset := stream.Offset()
+				skip := map[logframe.Stream]int64{
+					logframe.Stdout: stream.Skip(logframe.Stdout),
+					logframe.Stderr: stream.Skip(logframe.Stderr),
+				}
+				cmd.CloseLogStream(stream)
+
+				// The partial line of stdout comes first in the log, so the stderr line has to be skipped
+				So(offset, ShouldEqual, 0)
+				So(skip, ShouldResemble, map[logframe.Stream]int64{logframe.Stdout: 0, logframe.Stderr: 4})
+
+				stream, err = cmd.NewLogStream(ctx, LogStreamOptions{Lines: true, Offset: offset, Skip: skip})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "onetwo\n")
+			})
+
+			Convey("Should resume in the middle of a line split by the maximum length", func() {
+				cmd := NewCommand("resume-split", []string{"echo", "abcdefgh"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				options := LogStreamOptions{Lines: true, LineOptions: filestream.LineOptions{MaxLength: 3}}
+				stream, err := cmd.NewLogStream(ctx, options)
+				So(err, ShouldBeNil)
+				frame, _ := stream.ReadFrame()
+				So(string(frame.Data), ShouldEqual, "abc")
+				options.Offset = stream.Offset()
+				options.Skip = map[logframe.Stream]int64{logframe.Stdout: stream.Skip(logframe.Stdout)}
+				cmd.CloseLogStream(stream)
+
+				stream, err = cmd.NewLogStream(ctx, options)
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				frames, err := readFrames(stream)
+				So(err, ShouldBeNil)
+				So(withoutTimes(frames), ShouldResemble, []logframe.Frame{
+					{Stream: logframe.Stdout, Data: []byte("def")},
+					{Stream: logframe.Stdout, Data: []byte("gh\n")},
+				})
+			})
+
+			Convey("Should skip data of each stream when resuming outside of the line mode", func() {
+				cmd := NewCommand("resume-skip", []string{"sh", "-c", "echo out; sleep 0.1; echo err >&2"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{Skip: map[logframe.Stream]int64{logframe.Stdout: 2, logframe.Stderr: 1}})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "t\nrr\n")
+			})
+
+			Convey("Should reject invalid line options", func() {
+				cmd := NewCommand("line-options", []string{"echo", "hello"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				cmd.Wait()
+
+				_, err := cmd.NewLogStream(ctx, LogStreamOptions{Lines: true, LineOptions: filestream.LineOptions{MaxLength: -1}})
+				So(err, ShouldWrap, ErrInvalidOptions)
+				_, err = cmd.NewLogStream(ctx, LogStreamOptions{Lines: true, LineOptions: filestream.LineOptions{IdleTimeout: time.Nanosecond}})
+				So(err, ShouldWrap, ErrInvalidOptions)
+				_, err = cmd.NewLogStream(ctx, LogStreamOptions{LastLines: 1, Skip: map[logframe.Stream]int64{logframe.Stdout: 1}})
+				So(err, ShouldWrap, ErrInvalidOptions)
+			})
+
+			Convey("Should return the current output of a running command when not tailing", func() {
+				cmd := NewCommand("partial", []string{"sh", "-c", "echo hello; sleep 100"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				defer cmd.Wait()
+				defer cmd.Kill()
+
+				// Give the command a chance to print something
+				time.Sleep(500 * time.Millisecond)
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "hello\n")
+			})
+
+			Convey("Should stream the output of a running command until it finishes", func() {
+				cmd := NewCommand("stream", []string{"sh", "-c", "echo one; sleep 1; echo two"}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{Tail: true})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				done := make(chan string)
+				go func() {
+					output, _ := readLogStream(stream)
+					done <- output
+				}()
+
+				select {
+				case output := <-done:
+					So(output, ShouldEqual, "one\ntwo\n")
+				case <-time.After(10 * time.Second):
+					So("stream did not finish", ShouldBeEmpty)
+				}
+			})
+
+			Convey("Should catch up with the output no longer kept in memory while tailing", func() {
+				size := logBufferSize + 1000
+				cmd := NewCommand("catchup", []string{"sh", "-c", fmt.Sprintf("head -c %d /dev/zero; sleep 0.5; echo done", size)}, logsDir)
+				So(cmd.Start(), ShouldBeNil)
+				time.Sleep(200 * time.Millisecond)
+
+				stream, err := cmd.NewLogStream(ctx, LogStreamOptions{Tail: true})
+				So(err, ShouldBeNil)
+				defer cmd.CloseLogStream(stream)
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(len(output), ShouldEqual, size+len("done\n"))
+				So(output, ShouldEndWith, "done\n")
+			})
+
+			Convey("Should continue from the retained output when some has been removed while reading", func() {
+				buffer := &bytes.Buffer{}
+				writer := logframe.NewWriter(buffer)
+				for _, data := range []string{"one", "two", "three"} {
+					writer.WriteFrame(logframe.Stdout, []byte(data))
+				}
+
+				// The log is removed in the middle of the second frame, the reader skips to the third one
+				reader := &removingReader{log: buffer.Bytes(), removeAt: 25, skipTo: 40}
+				stream := newLogStream(reader, logframe.Position{}, LogStreamOptions{})
+				defer stream.close()
+
+				output, err := readLogStream(stream)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "onethree")
+				So(stream.Offset(), ShouldEqual, len(buffer.Bytes()))
+			})
+		})
+
+		Reset(func() {
+			os.RemoveAll(logsDir)
+		})
+	})
+}
@@ -0,0 +1,179 @@
+package container_exec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// A special argv[0] used to tell the re-executed binary it needs to act as a container init
+const initArg0 = "container_exec-init"
+
+// Namespaces created for each command
+const cloneFlags = syscall.CLONE_NEWPID | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET
+
+// Descriptor the init reports the wait status of the command through (passed via exec.Cmd.ExtraFiles)
+const exitStatusFd = 3
+
+// Descriptor of the cgroup directory the init puts the command into (only passed when cgroups are used)
+const cgroupFd = 4
+
+// Signals the init passes on to the command. As PID 1 of its namespace, the command would not receive
+// signals sent from the host unless it handles them, so they are delivered to the init instead.
+var forwardedSignals = []os.Signal{
+	syscall.SIGTERM,
+	syscall.SIGINT,
+	syscall.SIGHUP,
+	syscall.SIGQUIT,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+}
+
+// Returns true if a given signal reaches the command when sent to its init. Killing the init takes down
+// the whole namespace, while other signals not forwarded by the init would only reach the init itself.
+func isSupportedSignal(signal syscall.Signal) bool {
+	if signal == syscall.SIGKILL {
+		return true
+	}
+	for _, forwarded := range forwardedSignals {
+		if forwarded == signal {
+			return true
+		}
+	}
+	return false
+}
+
+// Init must be called at the very beginning of main() by any binary using the library.
+// If the binary has been re-executed by the process manager to start a command, Init sets up
+// the container environment, runs the command and exits once it is finished (never returning).
+// Otherwise, it returns false and the binary should continue as usual.
+func Init() bool {
+	if len(os.Args) < 3 || os.Args[0] != initArg0 {
+		return false
+	}
+
+	// os.Args: initArg0, <binary path>, <command argv...>
+	err := initContainer()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to initialize the container:", err)
+		os.Exit(127)
+	}
+
+	os.Exit(runInit(os.Args[1], os.Args[2:]))
+	return true
+}
+
+//-------------------------------------------------------------------------------------------------
+// Returns the argv for re-executing the current binary as a container init for a given command
+func initArgs(binary string, command []string) []string {
+	return append([]string{initArg0, binary}, command...)
+}
+
+// Sets up the environment of a freshly created container (we're PID 1 in new PID, mount and network namespaces)
+func initContainer() error {
+	// Make sure none of our mounts propagate back to the host
+	err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, "")
+	if err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+
+	// Replace the host /proc with the one showing only processes from our PID namespace
+	err = syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+	if err != nil {
+		return fmt.Errorf("failed to mount /proc: %w", err)
+	}
+	return nil
+}
+
+// Starts the command as a child of the init, forwarding signals to it and reaping orphaned processes until it exits.
+// The wait status of the command is reported to the server, since the init can't be killed by a signal itself.
+// Returns the exit code for the init, used by the server if the status could not be reported.
+func runInit(commandPath string, argv []string) int {
+	// The command must not inherit the status pipe or the cgroup directory
+	syscall.CloseOnExec(exitStatusFd)
+	sysAttr := &syscall.SysProcAttr{Setpgid: true}
+	if isDirectory(cgroupFd) {
+		syscall.CloseOnExec(cgroupFd)
+		sysAttr.UseCgroupFD = true
+		sysAttr.CgroupFD = cgroupFd
+	}
+
+	// Subscribe before starting the command, so that we don't miss it exiting right away
+	signals := make(chan os.Signal, 16)
+	signal.Notify(signals, append(forwardedSignals, syscall.SIGCHLD)...)
+
+	// The command gets its own process group, so that signals reach all of its processes
+	pid, err := syscall.ForkExec(commandPath, argv, &syscall.ProcAttr{
+		Env:   os.Environ(),
+		Files: []uintptr{0, 1, 2},
+		Sys:   sysAttr,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to execute '%s': %v\n", commandPath, err)
+		return 127
+	}
+
+	for sig := range signals {
+		if sig != syscall.SIGCHLD {
+			_ = syscall.Kill(-pid, sig.(syscall.Signal)) // The group may be gone if the command has just exited
+			continue
+		}
+
+		// Signals are coalesced, so we reap everything that has exited so far. Once the command is gone,
+		// the init exits and the kernel kills whatever is left in the namespace.
+		for {
+			var status syscall.WaitStatus
+			reaped, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+			if err != nil || reaped <= 0 {
+				break
+			}
+			if reaped == pid {
+				reportExitStatus(status)
+				return initExitCode(status)
+			}
+		}
+	}
+	return 127 // Unreachable, the signal channel is never closed
+}
+
+// Returns true if a given descriptor is open and refers to a directory
+func isDirectory(fd int) bool {
+	var stat syscall.Stat_t
+	err := syscall.Fstat(fd, &stat)
+	return err == nil && stat.Mode&syscall.S_IFMT == syscall.S_IFDIR
+}
+
+// Returns true if a given host process is a container init (see initArgs)
+func isInitProcess(pid int) bool {
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	return err == nil && bytes.HasPrefix(cmdline, []byte(initArg0+"\x00"))
+}
+
+// Passes the wait status of the command to the server
+func reportExitStatus(status syscall.WaitStatus) {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, uint32(status))
+	_, _ = syscall.Write(exitStatusFd, data) // The server falls back to the exit code of the init
+}
+
+// Reads the wait status of the command reported by the init (see reportExitStatus), ok is false if nothing was reported
+func readExitStatus(file *os.File) (status syscall.WaitStatus, ok bool) {
+	data := make([]byte, 4)
+	_, err := io.ReadFull(file, data)
+	if err != nil {
+		return 0, false
+	}
+	return syscall.WaitStatus(binary.LittleEndian.Uint32(data)), true
+}
+
+// Returns the exit code of the init for a given command status, following the shell convention for signals
+func initExitCode(status syscall.WaitStatus) int {
+	if status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return status.ExitStatus()
+}
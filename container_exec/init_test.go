@@ -0,0 +1,81 @@
+package container_exec
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Commands are started by re-executing the test binary, which needs to turn into a container init
+func TestMain(m *testing.M) {
+	if Init() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestContainerIsolation(t *testing.T) {
+	Convey("Commands", t, func() {
+		logsDir, _ := os.MkdirTemp("", "init_test")
+
+		// Runs a shell script in a container and returns its output
+		run := func(script string) string {
+			cmd := NewCommand("isolation", []string{"sh", "-c", script}, logsDir)
+			So(cmd.Start(), ShouldBeNil)
+			cmd.Wait()
+			So(cmd.ResultCode(), ShouldEqual, 0)
+
+			return strings.TrimSpace(commandOutput(cmd))
+		}
+
+		Convey("Should run as a child of the init in a new PID namespace", func() {
+			So(run("cat /proc/1/cmdline | tr '\\0' ' '"), ShouldStartWith, initArg0)
+			So(run("echo $$"), ShouldNotEqual, "1")
+		})
+
+		Convey("Should only see their own processes in /proc", func() {
+			So(run("set -- /proc/[0-9]*; echo $#"), ShouldEqual, "2") // the init and the shell
+		})
+
+		Convey("Should have orphaned processes reaped by the init", func() {
+			So(run("(true &); sleep 0.2; cat /proc/[0-9]*/stat | awk '$3 == \"Z\"' | wc -l"), ShouldEqual, "0")
+		})
+
+		Convey("Should receive the signals sent to the init", func() {
+			cmd := NewCommand("signal", []string{"sleep", "100"}, logsDir)
+			So(cmd.Start(), ShouldBeNil)
+			time.Sleep(100 * time.Millisecond) // Give the init time to start the command
+
+			syscall.Kill(cmd.Pid(), syscall.SIGINT)
+			cmd.Wait()
+			So(cmd.Exited(), ShouldBeFalse)
+			So(cmd.Signal(), ShouldEqual, syscall.SIGINT)
+		})
+
+		Convey("Should report the exit code of the command", func() {
+			cmd := NewCommand("exit", []string{"sh", "-c", "exit 7"}, logsDir)
+			So(cmd.Start(), ShouldBeNil)
+			cmd.Wait()
+			So(cmd.Exited(), ShouldBeTrue)
+			So(cmd.ResultCode(), ShouldEqual, 7)
+		})
+
+		Convey("Should not see host network interfaces", func() {
+			So(run("tail -n +3 /proc/net/dev | cut -d: -f1 | tr -d ' '"), ShouldEqual, "lo")
+		})
+
+		Convey("Should not affect the host /proc", func() {
+			run("true")
+			_, err := os.Stat("/proc/self/exe")
+			So(err, ShouldBeNil)
+		})
+
+		Reset(func() {
+			os.RemoveAll(logsDir)
+		})
+	})
+}
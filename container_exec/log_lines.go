@@ -0,0 +1,231 @@
+package container_exec
+
+import (
+	"time"
+
+	"teleport-exec/filestream"
+	"teleport-exec/logframe"
+)
+
+// Order in which partial lines of the streams are flushed
+var lineStreams = []logframe.Stream{logframe.Stdout, logframe.Stderr}
+
+// Amount of data of each output stream, indexed by logframe.Stream
+type streamSizes [logframe.Stderr + 1]int64
+
+// lineFrames splits the output of each stream into lines (see filestream.LineBuffer),
+// returning partial lines when there is no more output for a while or the log has ended.
+// Frames are read by a background goroutine, so that we could stop waiting for them when idle.
+type lineFrames struct {
+	idleTimeout time.Duration
+	results     chan frameResult // Frames read by the background goroutine
+	done        chan bool        // Closed to stop the background goroutine
+
+	buffers  map[logframe.Stream]*streamLines // Pending output of each stream
+	current  logframe.Stream                  // Stream of the last frame received, checked for complete lines first
+	offset   int64                            // Offset right after the last frame received
+	received streamSizes                      // Data of each stream received since the initial offset, including skipped data
+	skip     streamSizes                      // Data at the start of each stream still to be skipped
+	err      error                            // Error which stopped the background goroutine
+}
+
+// Result of reading a frame from the log
+type frameResult struct {
+	frame  logframe.Frame
+	offset int64 // Offset right after the frame
+	err    error
+}
+
+// Pending output of a stream along with the offsets and capture times of the frames it came from.
+// The data received before each of these frames is kept, so that we could tell how much has been returned since.
+type streamLines struct {
+	*filestream.LineBuffer
+	start         int64       // Offset of the frame holding the first pending byte
+	startTime     time.Time   // Capture time of the frame holding the first pending byte
+	startReceived streamSizes // Data received before the frame holding the first pending byte
+	lastStart     int64       // Offset of the last frame added to the buffer
+	lastTime      time.Time   // Capture time of the last frame added to the buffer
+	lastReceived  streamSizes // Data received before the last frame added to the buffer
+	lastSize      int         // Data size of the last frame added to the buffer
+}
+
+// Creates a line splitter reading frames with a given function, which returns the offset after the last frame.
+// The given amount of data at the start of each stream is skipped, as it has already been returned before.
+func newLineFrames(read func() (logframe.Frame, error), offset func() int64, skip streamSizes, options filestream.LineOptions) *lineFrames {
+	if options.IdleTimeout <= 0 {
+		options.IdleTimeout = filestream.DefaultIdleTimeout
+	}
+
+	l := &lineFrames{
+		idleTimeout: options.IdleTimeout,
+		results:     make(chan frameResult),
+		done:        make(chan bool),
+		buffers:     make(map[logframe.Stream]*streamLines),
+		offset:      offset(),
+		skip:        skip,
+	}
+	for _, stream := range lineStreams {
+		l.buffers[stream] = &streamLines{LineBuffer: filestream.NewLineBuffer(options.MaxLength)}
+	}
+
+	go l.readFrames(read, offset)
+	return l
+}
+
+// ReadFrame returns the next line of one of the streams, marked with the capture time of its beginning
+func (l *lineFrames) ReadFrame() (logframe.Frame, error) {
+	for {
+		line, capturedAt, ok := l.buffers[l.current].next()
+		if ok {
+			return logframe.Frame{Stream: l.current, Time: capturedAt, Data: line}, nil
+		}
+
+		if l.err != nil {
+			return l.flush(func(buffer *streamLines) []byte { return buffer.Drain() }, l.err)
+		}
+
+		if !l.pending() {
+			l.receive(<-l.results)
+			continue
+		}
+
+		// There is a partial line, so we shouldn't wait for the rest of it forever
+		timer := time.NewTimer(l.idleTimeout)
+		select {
+		case result := <-l.results:
+			timer.Stop()
+			l.receive(result)
+		case <-timer.C:
+			frame, err := l.flush(func(buffer *streamLines) []byte { return buffer.Flush() }, nil)
+			if len(frame.Data) > 0 {
+				return frame, err
+			}
+		}
+	}
+}
+
+// Position returns the offset of the first frame with some data which hasn't been returned yet,
+// along with the amount of data of each stream starting at that offset which has already been returned
+func (l *lineFrames) Position() (int64, streamSizes) {
+	offset, before := l.offset, l.received
+	for _, buffer := range l.buffers {
+		if buffer.Len() > 0 && buffer.start < offset {
+			offset, before = buffer.start, buffer.startReceived
+		}
+	}
+
+	var returned streamSizes
+	for _, stream := range lineStreams {
+		returned[stream] = l.received[stream] - int64(l.buffers[stream].Len()) - before[stream]
+	}
+	return offset, returned
+}
+
+// Close stops the background goroutine
+func (l *lineFrames) Close() {
+	close(l.done)
+}
+
+//-------------------------------------------------------------------------------------------------
+// Reads frames in the background, until there is an error
+func (l *lineFrames) readFrames(read func() (logframe.Frame, error), offset func() int64) {
+	for {
+		frame, err := read()
+
+		select {
+		case l.results <- frameResult{frame: frame, offset: offset(), err: err}:
+		case <-l.done:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Adds a frame read by the background goroutine to the buffer of its stream
+func (l *lineFrames) receive(result frameResult) {
+	start := l.offset
+	l.offset = result.offset
+	if result.err != nil {
+		l.err = result.err
+		return
+	}
+
+	stream, data := result.frame.Stream, result.frame.Data
+	received := l.received
+	l.received[stream] += int64(len(data))
+
+	// Skipped data counts as returned, since it has been returned by the stream we are resuming
+	skip := l.skip[stream]
+	if skip > int64(len(data)) {
+		skip = int64(len(data))
+	}
+	l.skip[stream] -= skip
+	data = data[skip:]
+
+	buffer := l.buffers[stream]
+	if buffer.Len() == 0 {
+		buffer.start = start
+		buffer.startTime = result.frame.Time
+		buffer.startReceived = received
+	}
+	buffer.lastStart = start
+	buffer.lastTime = result.frame.Time
+	buffer.lastReceived = received
+	buffer.lastSize = len(data)
+	buffer.Write(data)
+	l.current = stream
+}
+
+// Returns true if any of the streams has a partial line
+func (l *lineFrames) pending() bool {
+	for _, buffer := range l.buffers {
+		if buffer.Len() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the data taken from the first stream having any, or an empty frame with a given error
+func (l *lineFrames) flush(take func(buffer *streamLines) []byte, err error) (logframe.Frame, error) {
+	for _, stream := range lineStreams {
+		buffer := l.buffers[stream]
+		if buffer.Len() == 0 {
+			continue
+		}
+
+		capturedAt := buffer.startTime
+		data := take(buffer)
+		buffer.updateStart()
+		if len(data) > 0 {
+			return logframe.Frame{Stream: stream, Time: capturedAt, Data: data}, nil
+		}
+	}
+	return logframe.Frame{}, err
+}
+
+// Returns the next complete line of the stream along with its capture time, if any
+func (b *streamLines) next() ([]byte, time.Time, bool) {
+	if b == nil {
+		return nil, time.Time{}, false
+	}
+
+	capturedAt := b.startTime
+	line, ok := b.Next()
+	if ok {
+		b.updateStart()
+	}
+	return line, capturedAt, ok
+}
+
+// Moves the start offset and time forward when all pending data comes from the last frame
+func (b *streamLines) updateStart() {
+	if b.Len() <= b.lastSize {
+		b.start = b.lastStart
+		b.startTime = b.lastTime
+		b.startReceived = b.lastReceived
+	}
+}
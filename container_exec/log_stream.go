@@ -0,0 +1,182 @@
+package container_exec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"teleport-exec/filestream"
+	"teleport-exec/logframe"
+)
+
+// Limits of the line mode options, so that clients could not make us buffer too much data or flush it too often
+const (
+	maxLineLength  = 1024 * 1024
+	minIdleTimeout = 10 * time.Millisecond
+)
+
+// LogStreamOptions describes which part of the command output a log stream returns
+type LogStreamOptions struct {
+	Tail        bool                      // Keep tailing the log until the command is finished
+	Stream      logframe.Stream           // Only return the output of a given stream (all streams if 0)
+	Offset      int64                     // Position in the log to start reading from (0 or a value returned by LogStream.Offset)
+	Skip        map[logframe.Stream]int64 // Data of each stream at the offset to skip (values returned by LogStream.Skip)
+	LastLines   int64                     // Start from the last N lines of the output (ignored if 0)
+	LastBytes   int64                     // Start from the last N bytes of the output (ignored if 0)
+	Lines       bool                      // Return whole lines of each stream instead of arbitrary chunks (see lineFrames)
+	LineOptions filestream.LineOptions    // Maximum line length and idle timeout in the line mode (defaults if zero)
+	Since       time.Time                 // Only return the output captured at or after this time (ignored if zero)
+	Until       time.Time                 // Only return the output captured at or before this time (ignored if zero)
+}
+
+// Validate makes sure the options could be used to open a log stream
+func (o LogStreamOptions) Validate() error {
+	if o.Offset < 0 || o.LastLines < 0 || o.LastBytes < 0 {
+		return fmt.Errorf("%w: log offsets and sizes must not be negative", ErrInvalidOptions)
+	}
+
+	starts := 0
+	for _, value := range []int64{o.Offset, o.LastLines, o.LastBytes} {
+		if value != 0 {
+			starts++
+		}
+	}
+	if starts > 1 {
+		return fmt.Errorf("%w: only one of offset, last lines or last bytes could be used", ErrInvalidOptions)
+	}
+
+	for _, skip := range o.Skip {
+		if skip < 0 {
+			return fmt.Errorf("%w: the amount of data to skip must not be negative", ErrInvalidOptions)
+		}
+		if skip > 0 && (o.LastLines > 0 || o.LastBytes > 0) {
+			return fmt.Errorf("%w: skipping data is only possible when starting from an offset", ErrInvalidOptions)
+		}
+	}
+
+	if o.LineOptions.MaxLength < 0 || o.LineOptions.MaxLength > maxLineLength {
+		return fmt.Errorf("%w: the maximum line length must be between 0 and %d bytes", ErrInvalidOptions, maxLineLength)
+	}
+	if o.LineOptions.IdleTimeout < 0 || (o.LineOptions.IdleTimeout > 0 && o.LineOptions.IdleTimeout < minIdleTimeout) {
+		return fmt.Errorf("%w: the idle timeout must be 0 or at least %v", ErrInvalidOptions, minIdleTimeout)
+	}
+
+	if !o.Since.IsZero() && !o.Until.IsZero() && o.Until.Before(o.Since) {
+		return fmt.Errorf("%w: the end of the time range is before its start", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// LogStream reads the command output frames from its log, each frame tagged with the stream it came from
+type LogStream struct {
+	reader io.ReadCloser           // The log, or its recent part kept in memory (see filestream.Broadcaster)
+	frames *filestream.FrameReader // Frames within the requested time range
+	stream logframe.Stream         // Output stream to return frames for (all streams if 0)
+	since  time.Time               // Start of the requested time range
+	until  time.Time               // End of the requested time range
+	skip   streamSizes             // Data at the start of each stream still to be skipped (done by lineFrames in the line mode)
+	lines  *lineFrames             // Splits the output into lines (nil if not in the line mode)
+}
+
+// Implemented by readers which could skip data removed by log rotation (see filestream.ErrDataRemoved)
+type offsetReader interface {
+	Offset() int64
+}
+
+func newLogStream(reader io.ReadCloser, position logframe.Position, options LogStreamOptions) *LogStream {
+	stream := &LogStream{
+		reader: reader,
+		frames: filestream.NewFrameReader(reader, position, options.Since, options.Until),
+		stream: options.Stream,
+		since:  options.Since,
+		until:  options.Until,
+	}
+
+	var skip streamSizes
+	for _, outputStream := range lineStreams {
+		skip[outputStream] = options.Skip[outputStream]
+	}
+
+	if options.Lines {
+		stream.lines = newLineFrames(stream.readFrame, stream.frameOffset, skip, options.LineOptions)
+	} else {
+		stream.skip = skip
+	}
+	return stream
+}
+
+// ReadFrame returns the next chunk (or line in the line mode) of the command output.
+// Returns io.EOF when the end of the log has been reached (or the command has finished in tail mode).
+func (s *LogStream) ReadFrame() (logframe.Frame, error) {
+	if s.lines != nil {
+		return s.lines.ReadFrame()
+	}
+	return s.readFrame()
+}
+
+// Offset returns the position in the log right after the last frame read.
+// In the line mode, it is the offset of the first frame with some data not returned yet (see Skip).
+func (s *LogStream) Offset() int64 {
+	if s.lines != nil {
+		offset, _ := s.lines.Position()
+		return offset
+	}
+	return s.frameOffset()
+}
+
+// Skip returns the amount of data of a given stream at Offset() which has already been returned.
+// A new stream started from the offset and skipping this data continues exactly where this one has stopped.
+func (s *LogStream) Skip(stream logframe.Stream) int64 {
+	if stream != logframe.Stdout && stream != logframe.Stderr {
+		return 0
+	}
+	if s.lines != nil {
+		_, skip := s.lines.Position()
+		return skip[stream]
+	}
+	return s.skip[stream]
+}
+
+// Returns the offset right after the last frame read
+func (s *LogStream) frameOffset() int64 {
+	return s.frames.Offset()
+}
+
+// Stops reading the log
+func (s *LogStream) close() error {
+	if s.lines != nil {
+		s.lines.Close()
+	}
+	return s.reader.Close()
+}
+
+// Returns the next frame of the requested stream within the requested time range
+func (s *LogStream) readFrame() (logframe.Frame, error) {
+	for {
+		frame, err := s.frames.ReadFrame()
+		if reader, ok := s.reader.(offsetReader); ok && errors.Is(err, filestream.ErrDataRemoved) {
+			// The rest of a partially read frame is gone, so we continue from the frame the reader has skipped to
+			position := logframe.Position{Offset: reader.Offset()}
+			s.frames = filestream.NewFrameReader(s.reader, position, s.since, s.until)
+			continue
+		}
+		if err != nil {
+			return frame, err
+		}
+
+		if !s.stream.Matches(frame.Stream) {
+			continue
+		}
+
+		skip := s.skip[frame.Stream]
+		if skip > int64(len(frame.Data)) {
+			skip = int64(len(frame.Data))
+		}
+		s.skip[frame.Stream] -= skip
+		frame.Data = frame.Data[skip:]
+		if len(frame.Data) > 0 {
+			return frame, nil
+		}
+	}
+}
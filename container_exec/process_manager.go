@@ -0,0 +1,295 @@
+package container_exec
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config describes process manager settings
+type Config struct {
+	LogsDir        string        // Directory used to store command log files
+	StateDir       string        // Directory used to persist the state of commands across restarts (not persisted if empty)
+	LogSegmentSize int64         // Size at which a command log is rotated into a new segment (never rotated if 0)
+	MaxLogSize     int64         // Maximum size of a command log, the oldest segments are removed beyond that (unlimited if 0, requires LogSegmentSize)
+	MaxCommandAge  time.Duration // How long finished commands are kept, see ReapCommands (kept forever if 0)
+	MaxFinished    int           // Maximum number of finished commands kept, the oldest ones are removed beyond that (unlimited if 0)
+	CgroupRoot     string        // Parent cgroup for all commands (cgroups are not used if empty)
+	Limits         Limits        // Default resource limits applied to each command
+	MaxLimits      Limits        // Ceilings for limits requested for specific commands (zero values mean no maximum)
+}
+
+// ErrInvalidOptions is returned when command or log stream options are invalid
+var ErrInvalidOptions = errors.New("invalid command options")
+
+// CommandOptions describes optional settings for starting a command
+type CommandOptions struct {
+	Owner      string   // Name of the client starting the command
+	Limits     *Limits  // Resource limits for the command (nil to use the default ones)
+	WorkingDir string   // Working directory for the command (the current directory if empty)
+	Env        []string // Extra environment variables ("KEY=VALUE") added to the current environment
+	Stdin      []byte   // Data fed into the command stdin (stdin is empty if nil)
+}
+
+// Validate makes sure the options could be used to start a command
+func (o CommandOptions) Validate() error {
+	if o.WorkingDir != "" && !path.IsAbs(o.WorkingDir) {
+		return fmt.Errorf("%w: working directory must be an absolute path", ErrInvalidOptions)
+	}
+
+	for _, variable := range o.Env {
+		if strings.Index(variable, "=") < 1 {
+			return fmt.Errorf("%w: environment variable '%s' must be in the KEY=VALUE format", ErrInvalidOptions, variable)
+		}
+	}
+	return nil
+}
+
+type ProcessManager struct {
+	config Config
+	store  *Store // Persists the state of commands (nil if not persisted)
+
+	mu       sync.RWMutex        // Protects access to the commands map
+	commands map[string]*Command // All commands started by the manager (running and finished)
+}
+
+// NewProcessManager creates a process manager with a given configuration
+func NewProcessManager(config Config) (*ProcessManager, error) {
+	err := config.Limits.Validate(config.MaxLimits)
+	if err != nil {
+		return nil, fmt.Errorf("default limits do not fit into the maximum limits: %w", err)
+	}
+
+	// Only whole segments are removed, so the size could not be limited without rotation
+	if config.MaxLogSize > 0 && config.LogSegmentSize <= 0 {
+		return nil, fmt.Errorf("maximum log size %d requires a log segment size", config.MaxLogSize)
+	}
+	if config.MaxLogSize > 0 && config.MaxLogSize < config.LogSegmentSize {
+		return nil, fmt.Errorf("maximum log size %d is smaller than the log segment size %d", config.MaxLogSize, config.LogSegmentSize)
+	}
+
+	err = os.MkdirAll(config.LogsDir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logs directory '%s': %w", config.LogsDir, err)
+	}
+
+	if config.CgroupRoot != "" {
+		err = SetupCgroupRoot(config.CgroupRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pm := &ProcessManager{
+		config:   config,
+		commands: make(map[string]*Command),
+	}
+
+	if config.StateDir != "" {
+		err = pm.restoreCommands()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pm, nil
+}
+
+// DefaultLimits returns the limits applied to commands unless requested otherwise
+func (pm *ProcessManager) DefaultLimits() Limits {
+	return pm.config.Limits
+}
+
+// StartCommand starts a given command and returns a Command instance used to manage it.
+// Options are validated (see ErrInvalidOptions) and requested limits are checked against
+// the configured maximums (see ErrInvalidLimits).
+func (pm *ProcessManager) StartCommand(command []string, options CommandOptions) (*Command, error) {
+	err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	limits := pm.config.Limits
+	if options.Limits != nil {
+		err = options.Limits.Validate(pm.config.MaxLimits)
+		if err != nil {
+			return nil, err
+		}
+		limits = *options.Limits
+		limits.IODevices = pm.config.Limits.IODevices
+	}
+
+	cmd := NewCommand(uuid.NewString(), command, pm.config.LogsDir)
+	cmd.Owner = options.Owner
+	cmd.WorkingDir = options.WorkingDir
+	cmd.Env = options.Env
+	cmd.stdin = options.Stdin
+	cmd.logSegmentSize = pm.config.LogSegmentSize
+	cmd.maxLogSize = pm.config.MaxLogSize
+	cmd.store = pm.store
+
+	if pm.config.CgroupRoot != "" {
+		cg, err := newCgroup(pm.config.CgroupRoot, cmd.Id, limits)
+		if err != nil {
+			return nil, err
+		}
+		cmd.cgroup = cg
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.commands[cmd.Id] = cmd
+
+	return cmd, nil
+}
+
+// FindCommand returns a command with a given id or nil if it does not exist
+func (pm *ProcessManager) FindCommand(commandId string) *Command {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.commands[commandId]
+}
+
+// StopCommand kills a running command with a given id
+func (pm *ProcessManager) StopCommand(commandId string) error {
+	cmd := pm.FindCommand(commandId)
+	if cmd == nil {
+		return fmt.Errorf("command %s not found", commandId)
+	}
+	return cmd.Kill()
+}
+
+// DeleteCommand removes a finished command along with its log (see ErrCommandRunning)
+func (pm *ProcessManager) DeleteCommand(commandId string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	cmd := pm.commands[commandId]
+	if cmd == nil {
+		return fmt.Errorf("command %s not found", commandId)
+	}
+
+	err := cmd.remove()
+	if err != nil {
+		return err
+	}
+	delete(pm.commands, commandId)
+	return nil
+}
+
+// ReapCommands removes the finished commands which have expired at a given time according to the retention settings
+// (MaxCommandAge and MaxFinished). Returns the ids of removed commands and the last error for commands which failed to be removed.
+func (pm *ProcessManager) ReapCommands(now time.Time) ([]string, error) {
+	expired := pm.takeExpiredCommands(now)
+
+	// Removing the files could take a while, so it is done without blocking other calls.
+	// Commands which could not be removed are put back, so that the next run would try again.
+	var removed []string
+	var lastErr error
+	for _, cmd := range expired {
+		err := cmd.remove()
+		if err != nil {
+			pm.mu.Lock()
+			pm.commands[cmd.Id] = cmd
+			pm.mu.Unlock()
+			lastErr = err
+			continue
+		}
+		removed = append(removed, cmd.Id)
+	}
+	return removed, lastErr
+}
+
+// Commands returns a list of all commands known to the manager
+func (pm *ProcessManager) Commands() []*Command {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	commands := make([]*Command, 0, len(pm.commands))
+	for _, cmd := range pm.commands {
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+//-------------------------------------------------------------------------------------------------
+// Unregisters and returns the finished commands which have expired at a given time (see ReapCommands)
+func (pm *ProcessManager) takeExpiredCommands(now time.Time) []*Command {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	type finishedCommand struct {
+		cmd        *Command
+		finishedAt time.Time
+	}
+
+	var finished []finishedCommand
+	for _, cmd := range pm.commands {
+		cmd.mu.RLock()
+		if !cmd.running {
+			finished = append(finished, finishedCommand{cmd, cmd.finishedTime()})
+		}
+		cmd.mu.RUnlock()
+	}
+
+	// The most recent commands first, so that the ones beyond the maximum count are the oldest
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].finishedAt.After(finished[j].finishedAt)
+	})
+
+	var expired []*Command
+	for i, command := range finished {
+		tooOld := pm.config.MaxCommandAge > 0 && now.Sub(command.finishedAt) > pm.config.MaxCommandAge
+		excess := pm.config.MaxFinished > 0 && i >= pm.config.MaxFinished
+		if tooOld || excess {
+			delete(pm.commands, command.cmd.Id)
+			expired = append(expired, command.cmd)
+		}
+	}
+	return expired
+}
+
+// Loads the commands persisted by a previous run of the server, marking the ones which were running as lost
+func (pm *ProcessManager) restoreCommands() error {
+	store, err := NewStore(pm.config.StateDir)
+	if err != nil {
+		return err
+	}
+
+	records, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		cmd := restoreCommand(record, store)
+		if record.Running {
+			// Nothing could manage the processes of the command anymore, so they must not keep running
+			err = killLeftovers(record)
+			if err != nil {
+				log.Println("Failed to clean up a lost command:", err)
+			}
+
+			err = cmd.save()
+			if err != nil {
+				return err
+			}
+		}
+		pm.commands[cmd.Id] = cmd
+	}
+
+	pm.store = store
+	return nil
+}
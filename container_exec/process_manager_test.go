@@ -0,0 +1,250 @@
+package container_exec
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"teleport-exec/filestream"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProcessManager(t *testing.T) {
+	Convey("ProcessManager", t, func() {
+		logsDir, _ := os.MkdirTemp("", "process_manager_test")
+		pm, err := NewProcessManager(Config{LogsDir: logsDir})
+		So(err, ShouldBeNil)
+
+		Convey("Should reject a maximum log size smaller than a log segment", func() {
+			_, err := NewProcessManager(Config{LogsDir: logsDir, LogSegmentSize: 1024, MaxLogSize: 512})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Should reject a maximum log size without log rotation", func() {
+			_, err := NewProcessManager(Config{LogsDir: logsDir, MaxLogSize: 512})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("StartCommand()", func() {
+			Convey("Should start a command and register it", func() {
+				cmd, err := pm.StartCommand([]string{"true"}, CommandOptions{Owner: "alice"})
+				So(err, ShouldBeNil)
+				So(cmd.Id, ShouldNotBeEmpty)
+				So(cmd.Owner, ShouldEqual, "alice")
+				So(pm.FindCommand(cmd.Id), ShouldEqual, cmd)
+				cmd.Wait()
+			})
+
+			Convey("Should reject limits exceeding the maximum", func() {
+				pm, err := NewProcessManager(Config{LogsDir: logsDir, Limits: Limits{MemoryBytes: 512}, MaxLimits: Limits{MemoryBytes: 1024}})
+				So(err, ShouldBeNil)
+
+				_, err = pm.StartCommand([]string{"true"}, CommandOptions{Limits: &Limits{MemoryBytes: 2048}})
+				So(err, ShouldWrap, ErrInvalidLimits)
+				So(pm.Commands(), ShouldBeEmpty)
+			})
+
+			Convey("Should reject invalid options", func() {
+				_, err := pm.StartCommand([]string{"true"}, CommandOptions{WorkingDir: "relative/dir"})
+				So(err, ShouldWrap, ErrInvalidOptions)
+
+				_, err = pm.StartCommand([]string{"true"}, CommandOptions{Env: []string{"=value"}})
+				So(err, ShouldWrap, ErrInvalidOptions)
+
+				_, err = pm.StartCommand([]string{"true"}, CommandOptions{Env: []string{"NO_VALUE"}})
+				So(err, ShouldWrap, ErrInvalidOptions)
+			})
+
+			Convey("Should not register commands that failed to start", func() {
+				_, err := pm.StartCommand([]string{"/no/such/binary"}, CommandOptions{Owner: "alice"})
+				So(err, ShouldNotBeNil)
+				So(pm.Commands(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When persisting the state of commands", func() {
+			stateDir, _ := os.MkdirTemp("", "process_manager_state")
+			config := Config{LogsDir: logsDir, StateDir: stateDir}
+			pm, err := NewProcessManager(config)
+			So(err, ShouldBeNil)
+
+			Convey("Should restore finished commands after a restart", func() {
+				cmd, _ := pm.StartCommand([]string{"echo", "hello"}, CommandOptions{Owner: "alice"})
+				cmd.Wait()
+
+				pm, err := NewProcessManager(config)
+				So(err, ShouldBeNil)
+				restored := pm.FindCommand(cmd.Id)
+				So(restored, ShouldNotBeNil)
+				So(restored.Owner, ShouldEqual, "alice")
+				So(restored.Running(), ShouldBeFalse)
+				So(restored.Exited(), ShouldBeTrue)
+				So(restored.ResultCode(), ShouldEqual, 0)
+				So(restored.StartTime().Equal(cmd.StartTime()), ShouldBeTrue)
+				So(restored.EndTime().Equal(cmd.EndTime()), ShouldBeTrue)
+				So(restored.Pid(), ShouldEqual, cmd.Pid())
+				So(commandOutput(restored), ShouldEqual, "hello\n")
+			})
+
+			Convey("Should mark commands running during a restart as lost", func() {
+				store, _ := NewStore(stateDir)
+				store.Save(CommandRecord{Id: "crashed", Command: []string{"sleep", "100"}, Running: true})
+
+				pm, err := NewProcessManager(config)
+				So(err, ShouldBeNil)
+				lost := pm.FindCommand("crashed")
+				So(lost.Running(), ShouldBeFalse)
+				So(lost.Lost(), ShouldBeTrue)
+				So(lost.ResultCode(), ShouldEqual, -1)
+
+				records, _ := store.Load()
+				So(records[0].Running, ShouldBeFalse)
+				So(records[0].Lost, ShouldBeTrue)
+			})
+
+			Convey("Should kill commands left running by a previous process manager", func() {
+				cmd, err := pm.StartCommand([]string{"sleep", "100"}, CommandOptions{})
+				So(err, ShouldBeNil)
+
+				_, err = NewProcessManager(config)
+				So(err, ShouldBeNil)
+
+				select {
+				case <-cmd.Done():
+				case <-time.After(5 * time.Second):
+				}
+				So(cmd.Running(), ShouldBeFalse)
+			})
+
+			Convey("Should kill the processes of lost commands", func() {
+				// Looks like a container init left behind by the previous server
+				leftover := exec.Command("sleep", "100")
+				leftover.Args[0] = initArg0
+				leftover.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+				So(leftover.Start(), ShouldBeNil)
+
+				// Does not, so it must not be killed even though the command used to have the same PID
+				unrelated := exec.Command("sleep", "100")
+				So(unrelated.Start(), ShouldBeNil)
+				defer unrelated.Wait()
+				defer unrelated.Process.Kill()
+
+				store, _ := NewStore(stateDir)
+				store.Save(CommandRecord{Id: "leftover", Command: []string{"sleep", "100"}, Running: true, Pid: leftover.Process.Pid})
+				store.Save(CommandRecord{Id: "reused", Command: []string{"sleep", "100"}, Running: true, Pid: unrelated.Process.Pid})
+
+				_, err := NewProcessManager(config)
+				So(err, ShouldBeNil)
+
+				leftover.Wait()
+				So(leftover.ProcessState.Sys().(syscall.WaitStatus).Signal(), ShouldEqual, syscall.SIGKILL)
+				So(unrelated.Process.Signal(syscall.Signal(0)), ShouldBeNil)
+			})
+
+			Convey("Should not persist commands whose state could not be saved when started", func() {
+				store, _ := NewStore(stateDir)
+				os.RemoveAll(stateDir)
+
+				cmd := NewCommand("unsaved", []string{"sleep", "100"}, logsDir)
+				cmd.store = store
+				So(cmd.Start(), ShouldNotBeNil)
+
+				os.MkdirAll(stateDir, 0700)
+				cmd.Wait()
+				records, _ := store.Load()
+				So(records, ShouldBeEmpty)
+			})
+
+			Reset(func() {
+				os.RemoveAll(stateDir)
+			})
+		})
+
+		Convey("FindCommand() should return nil for unknown commands", func() {
+			So(pm.FindCommand("unknown"), ShouldBeNil)
+		})
+
+		Convey("StopCommand()", func() {
+			Convey("Should kill a running command", func() {
+				cmd, _ := pm.StartCommand([]string{"sleep", "100"}, CommandOptions{Owner: "alice"})
+				So(pm.StopCommand(cmd.Id), ShouldBeNil)
+				cmd.Wait()
+				So(cmd.Running(), ShouldBeFalse)
+			})
+
+			Convey("Should return an error for unknown commands", func() {
+				So(pm.StopCommand("unknown"), ShouldNotBeNil)
+			})
+		})
+
+		Convey("DeleteCommand()", func() {
+			Convey("Should remove a finished command and its log", func() {
+				cmd, _ := pm.StartCommand([]string{"echo", "hello"}, CommandOptions{Owner: "alice"})
+				cmd.Wait()
+
+				So(pm.DeleteCommand(cmd.Id), ShouldBeNil)
+				So(pm.FindCommand(cmd.Id), ShouldBeNil)
+				_, err := os.Stat(filestream.SegmentName(cmd.LogFileName, 0))
+				So(os.IsNotExist(err), ShouldBeTrue)
+			})
+
+			Convey("Should refuse to remove a running command", func() {
+				cmd, _ := pm.StartCommand([]string{"sleep", "100"}, CommandOptions{Owner: "alice"})
+				defer cmd.Wait()
+				defer cmd.Kill()
+
+				So(pm.DeleteCommand(cmd.Id), ShouldWrap, ErrCommandRunning)
+				So(pm.FindCommand(cmd.Id), ShouldEqual, cmd)
+			})
+
+			Convey("Should return an error for unknown commands", func() {
+				So(pm.DeleteCommand("unknown"), ShouldNotBeNil)
+			})
+		})
+
+		Convey("ReapCommands()", func() {
+			pm, _ := NewProcessManager(Config{LogsDir: logsDir, MaxCommandAge: time.Hour, MaxFinished: 2})
+			var finished []*Command
+			for i := 0; i < 3; i++ {
+				cmd, _ := pm.StartCommand([]string{"true"}, CommandOptions{Owner: "alice"})
+				cmd.Wait()
+				finished = append(finished, cmd)
+			}
+			running, _ := pm.StartCommand([]string{"sleep", "100"}, CommandOptions{Owner: "alice"})
+			defer running.Wait()
+			defer running.Kill()
+
+			Convey("Should remove the oldest commands beyond the maximum count", func() {
+				removed, err := pm.ReapCommands(time.Now())
+				So(err, ShouldBeNil)
+				So(removed, ShouldResemble, []string{finished[0].Id})
+				So(pm.Commands(), ShouldHaveLength, 3)
+			})
+
+			Convey("Should remove the expired commands but keep the running ones", func() {
+				removed, err := pm.ReapCommands(time.Now().Add(2 * time.Hour))
+				So(err, ShouldBeNil)
+				So(removed, ShouldHaveLength, 3)
+				So(pm.Commands(), ShouldResemble, []*Command{running})
+			})
+		})
+
+		Convey("Commands() should return all started commands", func() {
+			first, _ := pm.StartCommand([]string{"true"}, CommandOptions{Owner: "alice"})
+			second, _ := pm.StartCommand([]string{"true"}, CommandOptions{Owner: "alice"})
+			first.Wait()
+			second.Wait()
+
+			So(pm.Commands(), ShouldHaveLength, 2)
+			So(pm.Commands(), ShouldContain, first)
+			So(pm.Commands(), ShouldContain, second)
+		})
+
+		Reset(func() {
+			os.RemoveAll(logsDir)
+		})
+	})
+}
@@ -0,0 +1,171 @@
+package container_exec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CommandRecord is the state of a command persisted in a Store
+type CommandRecord struct {
+	Id          string         `json:"id"`
+	Command     []string       `json:"command"`
+	Owner       string         `json:"owner"`
+	LogFileName string         `json:"log_file_name"`
+	StartTime   time.Time      `json:"start_time"`
+	EndTime     time.Time      `json:"end_time"` // Zero while the command is running
+	Running     bool           `json:"running"`
+	Exited      bool           `json:"exited"`
+	ResultCode  int32          `json:"result_code"`
+	Signal      syscall.Signal `json:"signal"`                // Zero unless the command has been killed by a signal
+	Pid         int            `json:"pid"`                   // Host PID of the container init running the command
+	CgroupPath  string         `json:"cgroup_path,omitempty"` // Empty if cgroups are not used
+	Usage       *ResourceUsage `json:"usage,omitempty"`       // Nil if cgroups are not used
+	Lost        bool           `json:"lost"`                  // The server has stopped while the command was running
+}
+
+// Store persists command records in a directory, one JSON file per command
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store keeping command records in a given directory
+func NewStore(dir string) (*Store, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state directory '%s': %w", dir, err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save writes a command record, replacing the previous one atomically
+func (s *Store) Save(record CommandRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode command %s state: %w", record.Id, err)
+	}
+
+	// Write into a temporary file first, so that a crash never leaves a partially written record behind
+	// (each save gets its own temporary file, as the same record could be saved concurrently).
+	tempName, err := writeTempFile(s.dir, record.Id+".json.*.tmp", data)
+	if err != nil {
+		return fmt.Errorf("failed to write command %s state: %w", record.Id, err)
+	}
+
+	err = os.Rename(tempName, s.fileName(record.Id))
+	if err != nil {
+		os.Remove(tempName)
+		return fmt.Errorf("failed to write command %s state: %w", record.Id, err)
+	}
+
+	// The rename itself is only durable once the directory has been synced
+	err = syncDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to write command %s state: %w", record.Id, err)
+	}
+	return nil
+}
+
+// Load reads all command records. Records which could not be read are logged and skipped,
+// so that a single damaged file doesn't prevent the others from being restored.
+// Temporary files left behind by a crash while saving are removed.
+func (s *Store) Load() ([]CommandRecord, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state directory '%s': %w", s.dir, err)
+	}
+
+	var records []CommandRecord
+	for _, entry := range entries {
+		// The crash happened before the rename, so the previous record of the command (if any) is still in place
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			err = os.Remove(path.Join(s.dir, entry.Name()))
+			if err != nil {
+				log.Println("Failed to remove a temporary command state file:", err)
+			}
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		record, err := s.load(entry.Name())
+		if err != nil {
+			log.Println("Skipping command state:", err)
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Delete removes the record of a given command (if any)
+func (s *Store) Delete(commandId string) error {
+	err := os.Remove(s.fileName(commandId))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete command %s state: %w", commandId, err)
+	}
+	return nil
+}
+
+//-------------------------------------------------------------------------------------------------
+// Reads a single command record from a given file of the store
+func (s *Store) load(name string) (CommandRecord, error) {
+	var record CommandRecord
+	data, err := os.ReadFile(path.Join(s.dir, name))
+	if err != nil {
+		return record, fmt.Errorf("failed to read command state '%s': %w", name, err)
+	}
+
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return record, fmt.Errorf("failed to decode command state '%s': %w", name, err)
+	}
+	if record.Id+".json" != name {
+		return record, fmt.Errorf("command state '%s' holds the state of command '%s'", name, record.Id)
+	}
+	return record, nil
+}
+
+// Returns the name of the file holding the record of a given command
+func (s *Store) fileName(commandId string) string {
+	return path.Join(s.dir, commandId+".json")
+}
+
+// Writes data into a new temporary file of a given directory (see os.CreateTemp for the pattern),
+// making sure it has reached the disk before returning. Returns the name of the file.
+func writeTempFile(dir string, pattern string, data []byte) (string, error) {
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// Makes sure the entries of a directory (e.g. a file renamed into it) have reached the disk
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
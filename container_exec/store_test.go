@@ -0,0 +1,82 @@
+package container_exec
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStore(t *testing.T) {
+	Convey("Store", t, func() {
+		dir, _ := os.MkdirTemp("", "store_test")
+		store, err := NewStore(dir)
+		So(err, ShouldBeNil)
+
+		record := CommandRecord{
+			Id:          "one",
+			Command:     []string{"echo", "hello"},
+			Owner:       "alice",
+			LogFileName: "/logs/one.log",
+			StartTime:   time.Unix(1600000000, 0).UTC(),
+			Running:     true,
+			ResultCode:  -1,
+		}
+
+		Convey("Should load saved records", func() {
+			So(store.Save(record), ShouldBeNil)
+
+			records, err := store.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldResemble, []CommandRecord{record})
+		})
+
+		Convey("Should replace the previous record of a command", func() {
+			store.Save(record)
+			record.Running = false
+			record.Exited = true
+			record.ResultCode = 0
+			So(store.Save(record), ShouldBeNil)
+
+			records, _ := store.Load()
+			So(records, ShouldResemble, []CommandRecord{record})
+		})
+
+		Convey("Should delete records", func() {
+			store.Save(record)
+			So(store.Delete(record.Id), ShouldBeNil)
+			So(store.Delete(record.Id), ShouldBeNil) // Already deleted
+
+			records, _ := store.Load()
+			So(records, ShouldBeEmpty)
+		})
+
+		Convey("Should skip records which could not be read", func() {
+			store.Save(record)
+			os.WriteFile(path.Join(dir, "two.json"), []byte("{\"id\":"), 0600)
+			os.WriteFile(path.Join(dir, "three.json"), []byte("{\"id\":\"four\"}"), 0600)
+
+			records, err := store.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldResemble, []CommandRecord{record})
+		})
+
+		Convey("Should skip and remove partially written records", func() {
+			tempName := path.Join(dir, "two.json.123456.tmp")
+			os.WriteFile(tempName, []byte("{\"id\":"), 0600)
+
+			records, err := store.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldBeEmpty)
+
+			_, err = os.Stat(tempName)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Reset(func() {
+			os.RemoveAll(dir)
+		})
+	})
+}
@@ -0,0 +1,286 @@
+package filestream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Returned internally when the data at a given offset is no longer kept in memory
+var errNotBuffered = errors.New("data is no longer buffered")
+
+// ErrDataRemoved is returned by a BroadcastReader when the data it was about to read has been removed from the file
+// (e.g. by rotation). Reading could be continued, but from a later offset (see BroadcastReader.Offset).
+var ErrDataRemoved = errors.New("data has been removed from the file")
+
+// Broadcaster writes data into an underlying writer (e.g. a log file) and keeps the most recent data
+// in a ring buffer, passing new data to the readers tailing it without reading it back from the file.
+// Readers falling behind the buffer catch up by reading the file.
+type Broadcaster struct {
+	writer io.Writer
+
+	mu      sync.Mutex // Protects access to the fields below
+	buffer  []byte     // Ring buffer with the most recent data, the byte at offset N is kept at N % len(buffer)
+	start   int64      // Offset of the first byte in the buffer
+	end     int64      // Offset right after the last byte written
+	written chan bool  // Closed (and replaced) whenever new data is written, or when the broadcaster is closed
+	closed  bool       // Set to true when Close() is first called
+}
+
+// NewBroadcaster creates a broadcaster writing into a given writer, keeping the last bufferSize bytes in memory
+func NewBroadcaster(writer io.Writer, bufferSize int) *Broadcaster {
+	return &Broadcaster{
+		writer:  writer,
+		buffer:  make([]byte, bufferSize),
+		written: make(chan bool),
+	}
+}
+
+// Write implements the io.Writer interface, writing data into the underlying writer and then passing it to the readers.
+// Returns io.ErrClosedPipe once the broadcaster is closed.
+func (b *Broadcaster) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	written, err := b.writer.Write(data)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.append(data[:written])
+	if !b.closed { // Close may have been called while writing, the readers have been woken up already then
+		close(b.written)
+		b.written = make(chan bool)
+	}
+	return written, err
+}
+
+// Close lets the readers know there will be no more data. The underlying writer needs to be closed separately.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		close(b.written)
+	}
+}
+
+// Size returns the offset right after the last write. Writes are passed to the underlying writer as a whole,
+// so unlike the size of the file it never points into the middle of a write.
+func (b *Broadcaster) Size() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.end
+}
+
+// NewReader returns a reader starting at a given offset. When tailing, the reader waits for more data at the end
+// until the broadcaster is closed. The open function is used to read the data which is no longer kept in memory.
+func (b *Broadcaster) NewReader(ctx context.Context, offset int64, tail bool, open func() (*FileStream, error)) *BroadcastReader {
+	return &BroadcastReader{
+		broadcaster: b,
+		offset:      offset,
+		tail:        tail,
+		open:        open,
+		ctx:         ctx,
+		done:        make(chan bool),
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+// Adds data to the ring buffer, replacing the oldest data
+func (b *Broadcaster) append(data []byte) {
+	size := int64(len(b.buffer))
+	if size == 0 {
+		b.end += int64(len(data))
+		b.start = b.end
+		return
+	}
+
+	// Only the end of the data fits into the buffer
+	if int64(len(data)) > size {
+		b.end += int64(len(data)) - size
+		data = data[int64(len(data))-size:]
+	}
+
+	for len(data) > 0 {
+		position := b.end % size
+		copied := copy(b.buffer[position:], data)
+		data = data[copied:]
+		b.end += int64(copied)
+	}
+
+	if b.end-b.start > size {
+		b.start = b.end - size
+	}
+}
+
+// Copies the data at a given offset from the ring buffer. Returns a channel to wait on if there is no data yet,
+// io.EOF if there will be no more data, or errNotBuffered if the data is no longer in the buffer.
+func (b *Broadcaster) readAt(data []byte, offset int64, tail bool) (int, chan bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if offset < b.start {
+		return 0, nil, errNotBuffered
+	}
+	if offset >= b.end {
+		if b.closed || !tail {
+			return 0, nil, io.EOF
+		}
+		return 0, b.written, nil
+	}
+
+	size := int64(len(b.buffer))
+	total := 0
+	for total < len(data) && offset < b.end {
+		position := offset % size
+		available := b.end - offset
+		if available > size-position {
+			available = size - position
+		}
+		copied := copy(data[total:], b.buffer[position:position+available])
+		total += copied
+		offset += int64(copied)
+	}
+	return total, nil, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+// BroadcastReader reads the data written through a Broadcaster (see NewReader)
+type BroadcastReader struct {
+	broadcaster *Broadcaster
+	offset      int64                       // Offset of the next byte to read
+	tail        bool                        // Wait for more data at the end (true) or stop (false)
+	open        func() (*FileStream, error) // Opens the file to catch up from
+	ctx         context.Context             // Used to abort reading when a client disconnects, etc
+	done        chan bool                   // Closed when the reader is closed
+
+	mu     sync.Mutex  // Protects access to the fields below
+	file   *FileStream // Used to catch up with the buffer (nil when reading from memory)
+	closed bool        // Set to true when Close() is first called
+}
+
+// Read implements the io.Reader interface, blocking for more data in tail mode.
+// Returns ErrDataRemoved when some data has been skipped, reading could be continued after that.
+func (r *BroadcastReader) Read(data []byte) (int, error) {
+	for {
+		select {
+		case <-r.done:
+			return 0, io.EOF
+		case <-r.ctx.Done():
+			return 0, io.EOF
+		default:
+		}
+
+		readBytes, written, err := r.broadcaster.readAt(data, r.offset, r.tail)
+		if err == errNotBuffered {
+			readBytes, err = r.readFile(data)
+			if readBytes > 0 || err != nil {
+				return readBytes, err
+			}
+			continue
+		}
+
+		// We have caught up with the buffer, so the file is not needed anymore
+		r.closeFile()
+
+		if readBytes > 0 || err != nil {
+			r.offset += int64(readBytes)
+			return readBytes, err
+		}
+
+		select {
+		case <-written:
+		case <-r.done:
+		case <-r.ctx.Done():
+		}
+	}
+}
+
+// Offset returns the offset of the next byte to read
+func (r *BroadcastReader) Offset() int64 {
+	return r.offset
+}
+
+// Close stops reading, unblocking a pending Read
+func (r *BroadcastReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+
+	if r.file != nil {
+		return r.file.Close()
+	}
+	return nil
+}
+
+// Reads the data which is no longer in memory from the file. Returns nothing at the end of the file.
+func (r *BroadcastReader) readFile(data []byte) (int, error) {
+	file, err := r.openFile()
+	if err != nil {
+		return 0, err
+	}
+
+	readBytes, err := file.Read(data)
+	r.offset += int64(readBytes)
+	if err == io.EOF {
+		r.closeFile()
+		return readBytes, nil
+	}
+	return readBytes, err
+}
+
+// Returns the file positioned at the current offset, opening it if needed.
+// Returns ErrDataRemoved if the offset had to be moved forward, the next call returns the file.
+func (r *BroadcastReader) openFile() (*FileStream, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		return r.file, nil
+	}
+	if r.closed {
+		return nil, io.EOF
+	}
+
+	file, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rotation may have removed the data already, so we continue from wherever the file starts
+	offset, err := file.Seek(r.offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r.file = file
+	if offset != r.offset {
+		r.offset = offset
+		return nil, ErrDataRemoved // The caller needs to know the data read so far is not followed by the data read next
+	}
+	return file, nil
+}
+
+// Closes the file used to catch up with the buffer
+func (r *BroadcastReader) closeFile() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
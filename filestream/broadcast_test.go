@@ -0,0 +1,136 @@
+package filestream
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBroadcaster(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Broadcaster", t, func() {
+		dir, _ := os.MkdirTemp("", "broadcast_test")
+		fileName := path.Join(dir, "test.log")
+		file, _ := os.Create(fileName)
+		broadcaster := NewBroadcaster(file, 8)
+
+		opened := 0
+		open := func() (*FileStream, error) {
+			opened++
+			return New(ctx, fileName)
+		}
+
+		Convey("Should write the data into the underlying writer", func() {
+			broadcaster.Write([]byte("hello"))
+			data, _ := os.ReadFile(fileName)
+			So(string(data), ShouldEqual, "hello")
+		})
+
+		Convey("Should reject writes once closed", func() {
+			broadcaster.Close()
+			written, err := broadcaster.Write([]byte("hello"))
+			So(err, ShouldEqual, io.ErrClosedPipe)
+			So(written, ShouldEqual, 0)
+
+			data, _ := os.ReadFile(fileName)
+			So(string(data), ShouldEqual, "")
+		})
+
+		Convey("Should read recent data from memory", func() {
+			broadcaster.Write([]byte("hello"))
+			reader := broadcaster.NewReader(ctx, 1, false, open)
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "ello")
+			So(opened, ShouldEqual, 0)
+		})
+
+		Convey("Should catch up from the file when the data is no longer in memory", func() {
+			broadcaster.Write([]byte("hello, "))
+			broadcaster.Write([]byte("world!"))
+			reader := broadcaster.NewReader(ctx, 0, false, open)
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "hello, world!")
+			So(opened, ShouldEqual, 1)
+		})
+
+		Convey("Should wait for new data when tailing until closed", func() {
+			broadcaster.Write([]byte("one"))
+			reader := broadcaster.NewReader(ctx, 0, true, open)
+			defer reader.Close()
+
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				broadcaster.Write([]byte("two"))
+				time.Sleep(100 * time.Millisecond)
+				broadcaster.Close()
+			}()
+
+			data, err := io.ReadAll(reader)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "onetwo")
+		})
+
+		Convey("Should return an EOF when the reader is closed while waiting", func() {
+			reader := broadcaster.NewReader(ctx, 0, true, open)
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				reader.Close()
+			}()
+
+			_, err := reader.Read(make([]byte, 8))
+			So(err, ShouldEqual, io.EOF)
+		})
+
+		Convey("Should keep the data larger than the buffer in the file only", func() {
+			broadcaster.Write([]byte("0123456789abcdef"))
+			reader := broadcaster.NewReader(ctx, 4, false, open)
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "456789abcdef")
+			So(opened, ShouldEqual, 1)
+		})
+
+		Convey("Should report the data removed by rotation before it could be read", func() {
+			segments, _ := NewSegmentWriter(fileName, 4, 8)
+			defer segments.Close()
+			broadcaster := NewBroadcaster(segments, 4)
+			for _, data := range []string{"aaaa", "bbbb", "cccc", "dddd"} {
+				broadcaster.Write([]byte(data))
+			}
+
+			reader := broadcaster.NewReader(ctx, 0, false, func() (*FileStream, error) {
+				return New(ctx, fileName, WithSegments())
+			})
+			defer reader.Close()
+
+			_, err := reader.Read(make([]byte, 4))
+			So(err, ShouldEqual, ErrDataRemoved)
+			So(reader.Offset(), ShouldBeGreaterThan, 0)
+
+			offset := reader.Offset()
+			data, err := io.ReadAll(reader)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "aaaabbbbccccdddd"[offset:])
+		})
+
+		Reset(func() {
+			broadcaster.Close()
+			file.Close()
+			os.RemoveAll(dir)
+		})
+	})
+}
@@ -2,18 +2,39 @@ package filestream
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"strings"
 	"sync"
+	"time"
 
-	"github.com/fsnotify/fsnotify"
+	"teleport-exec/logframe"
 )
 
+// Size of the chunks read when scanning a file backwards
+const scanChunkSize = 64 * 1024
+
+// Errors returned by a tailing stream when the file changes under it (unless following the file by name, see WithFollowName)
+var (
+	ErrTruncated = errors.New("file has been truncated")
+	ErrRemoved   = errors.New("file has been removed or renamed")
+)
+
+// ErrTimeout is returned by a tailing stream when no data arrives within the read timeout (see WithReadTimeout)
+var ErrTimeout = errors.New("timed out waiting for more data")
+
 type FileStream struct {
 	fileName string
 	reader   *os.File
-	watcher  *fsnotify.Watcher
+	watcher  watcher
+
+	segmented     bool          // Set to true when reading a rotated file (see WithSegments)
+	segmentOffset int64         // Offset of the current segment within the whole file
+	followName    bool          // Set to true to reopen the file by name when it is truncated, renamed or removed (see WithFollowName)
+	readTimeout   time.Duration // Maximum time Read waits for more data in the tailing mode (no limit if 0)
 
 	tailEnabled bool      // Controls what happens when we hit the EOF: wait for more (true) or stop (false)
 	logComplete chan bool // Used to notify the reader when the log is complete
@@ -24,32 +45,121 @@ type FileStream struct {
 	ctx      context.Context // Used to abort streaming when a client disconnects, etc
 }
 
-// New creates a FileStream instance for a given file
-func New(ctx context.Context, fileName string, tail bool) (*FileStream, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file '%s': %w", fileName, err)
+// Settings of a FileStream
+type options struct {
+	tail         bool
+	offset       int64
+	segmented    bool
+	followName   bool
+	watcher      WatcherBackend
+	pollInterval time.Duration
+	readTimeout  time.Duration
+}
+
+// Option configures a FileStream (see New)
+type Option func(*options)
+
+// WithTail makes the stream wait for more content at the end of the file (see TailEnabled)
+func WithTail(tail bool) Option {
+	return func(o *options) { o.tail = tail }
+}
+
+// WithOffset starts the stream at a given offset instead of the beginning of the file (see Seek)
+func WithOffset(offset int64) Option {
+	return func(o *options) { o.offset = offset }
+}
+
+// WithSegments reads a rotated file stored as a sequence of segments (see SegmentWriter).
+// The stream starts at the first retained segment and follows reads across the segments.
+// Offsets used by Seek are positions within the whole file.
+func WithSegments() Option {
+	return func(o *options) { o.segmented = true }
+}
+
+// WithFollowName makes a tailing stream follow the file by its name, like tail -F does. When the file is truncated,
+// the stream starts reading it from the beginning. When the file is renamed or removed, the stream reads the rest
+// of the old file, waits for a new file with the same name and then reads that one from the beginning.
+// Without it, the stream returns ErrTruncated or ErrRemoved in these cases.
+func WithFollowName() Option {
+	return func(o *options) { o.followName = true }
+}
+
+// WithWatcher selects how changes of the file are detected (AutoWatcher by default)
+func WithWatcher(backend WatcherBackend) Option {
+	return func(o *options) { o.watcher = backend }
+}
+
+// WithPollInterval sets how often the polling watcher checks the file (DefaultPollInterval by default)
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) { o.pollInterval = interval }
+}
+
+// WithReadTimeout makes Read return ErrTimeout when no data arrives within a given time in the tailing mode
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.readTimeout = timeout }
+}
+
+// New creates a FileStream instance for a given file. By default the stream reads the file
+// from the beginning and stops at its end (see Option for other settings).
+func New(ctx context.Context, fileName string, opts ...Option) (*FileStream, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	name := fileName
+	segmentOffset := int64(0)
+	// Watch the directory as well, so that we could see the file being replaced
+	watched := []string{fileName, path.Dir(fileName)}
+
+	if o.segmented {
+		segments, err := listSegments(fileName)
+		if err != nil {
+			return nil, err
+		}
+		if len(segments) == 0 {
+			return nil, fmt.Errorf("no segments found for '%s': %w", fileName, os.ErrNotExist)
+		}
+
+		name = segments[0].name
+		segmentOffset = segments[0].offset
+		// Watch the whole directory, so that we could see new segments being created
+		watched = []string{path.Dir(fileName)}
 	}
 
-	watcher, err := fsnotify.NewWatcher()
+	file, err := os.Open(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start a watcher: %w", err)
+		return nil, fmt.Errorf("failed to open file '%s': %w", name, err)
 	}
 
-	err = watcher.Add(fileName)
+	watcher, err := newWatcher(o.watcher, o.pollInterval, watched...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to add file '%s' to the watcher: %w", fileName, err)
+		file.Close()
+		return nil, err
+	}
+
+	s := &FileStream{
+		fileName:      fileName,
+		tailEnabled:   o.tail,
+		reader:        file,
+		watcher:       watcher,
+		segmented:     o.segmented,
+		segmentOffset: segmentOffset,
+		followName:    o.followName,
+		readTimeout:   o.readTimeout,
+		ctx:           ctx,
+		done:          make(chan bool),
+		logComplete:   make(chan bool),
 	}
 
-	return &FileStream{
-		fileName:    fileName,
-		tailEnabled: tail,
-		reader:      file,
-		watcher:     watcher,
-		ctx:         ctx,
-		done:        make(chan bool),
-		logComplete: make(chan bool),
-	}, nil
+	if o.offset != 0 {
+		_, err = s.Seek(o.offset, io.SeekStart)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to seek file '%s': %w", fileName, err)
+		}
+	}
+	return s, nil
 }
 
 // TailEnabled returns true if the stream is in a tailing mode (blocking at the end of a file, waiting for more content)
@@ -71,6 +181,79 @@ func (s *FileStream) DisableTail() {
 	}
 }
 
+// Seek implements the io.Seeker interface, setting the position of the next Read.
+// For rotated files, positions removed by rotation are replaced by the start of the first retained segment.
+// It must not be called concurrently with Read.
+func (s *FileStream) Seek(offset int64, whence int) (int64, error) {
+	if !s.segmented {
+		return s.reader.Seek(offset, whence)
+	}
+
+	current, err := s.reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	segments, end, err := s.segments()
+	if err != nil {
+		return 0, err
+	}
+
+	switch whence {
+	case io.SeekCurrent:
+		offset += s.segmentOffset + current
+	case io.SeekEnd:
+		offset += end
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("invalid offset %d in file '%s'", offset, s.fileName)
+	}
+
+	segment := findSegment(segments, offset)
+	if offset < segment.offset {
+		offset = segment.offset
+	}
+
+	err = s.openSegment(segment, offset-segment.offset)
+	if err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// SeekLastBytes positions the stream at the last n bytes of the file (or its beginning if the file is shorter).
+// It must not be called concurrently with Read.
+func (s *FileStream) SeekLastBytes(n int64) (int64, error) {
+	file, start, end, err := s.openReaderAt()
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	offset := end - n
+	if offset < start {
+		offset = start
+	}
+	return s.Seek(offset, io.SeekStart)
+}
+
+// SeekLastLines positions the stream at the beginning of the last n lines of the file, scanning it backwards.
+// Same as with tail -n, a newline at the very end of the file does not start a new line.
+// It must not be called concurrently with Read.
+func (s *FileStream) SeekLastLines(n int) (int64, error) {
+	file, start, end, err := s.openReaderAt()
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	offset, err := lastLinesOffset(io.NewSectionReader(file, start, end-start), end-start, n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan file '%s': %w", s.fileName, err)
+	}
+	return s.Seek(start+offset, io.SeekStart)
+}
+
 // Close stops any active watchers and closes the underlying file stream
 func (s *FileStream) Close() error {
 	s.mu.Lock()
@@ -92,24 +275,200 @@ func (s *FileStream) Close() error {
 }
 
 //-------------------------------------------------------------------------------------------------
-// Blocks until there is a write event on the file, or we're asked to stop waiting and/or reading
-func (s *FileStream) waitForChanges() {
+// Opens the whole file for random access, returning the range of available offsets
+func (s *FileStream) openReaderAt() (readerAt, int64, int64, error) {
+	if s.segmented {
+		segments, err := OpenSegments(s.fileName)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return segments, segments.Start(), segments.End(), nil
+	}
+
+	file, err := os.Open(s.fileName)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to open file '%s': %w", s.fileName, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, 0, fmt.Errorf("failed to stat file '%s': %w", s.fileName, err)
+	}
+	return file, 0, info.Size(), nil
+}
+
+// Random access to a file which needs to be closed when no longer used
+type readerAt interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// Returns the retained segments of a rotated file and the offset of its end
+func (s *FileStream) segments() ([]segment, int64, error) {
+	segments, err := listSegments(s.fileName)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(segments) == 0 {
+		return nil, 0, fmt.Errorf("no segments found for '%s': %w", s.fileName, os.ErrNotExist)
+	}
+
+	last := segments[len(segments)-1]
+	info, err := os.Stat(last.name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to stat file '%s': %w", last.name, err)
+	}
+	return segments, last.offset + info.Size(), nil
+}
+
+// Makes a given segment the current one, positioned at a given offset within the segment
+func (s *FileStream) openSegment(segment segment, offset int64) error {
+	return s.openFile(segment.name, offset, func() {
+		s.segmentOffset = segment.offset
+	})
+}
+
+// Replaces the current reader with a given file positioned at a given offset, calling update while holding the lock
+func (s *FileStream) openFile(fileName string, offset int64, update func()) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to open file '%s': %w", fileName, err)
+	}
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to seek file '%s': %w", fileName, err)
+	}
+
+	// Close() could be closing the current file at the same time
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isClosed {
+		file.Close()
+		return io.EOF
+	}
+
+	_ = s.reader.Close()
+	s.reader = file
+	update()
+	return nil
+}
+
+// Checks whether the file has been truncated, renamed or removed after we've read everything from it.
+// Returns true if the stream has been repositioned to follow the file by its name and could be read again.
+func (s *FileStream) checkFile() (bool, error) {
+	current, err := s.reader.Stat()
+	if err != nil {
+		return false, fmt.Errorf("failed to stat file '%s': %w", s.fileName, err)
+	}
+
+	named, err := os.Stat(s.fileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to stat file '%s': %w", s.fileName, err)
+	}
+
+	// The file is gone or has been replaced with another one
+	if err != nil || !os.SameFile(current, named) {
+		if !s.followName {
+			return false, fmt.Errorf("%w: '%s'", ErrRemoved, s.fileName)
+		}
+		if err != nil {
+			return false, nil // Wait for a new file to appear
+		}
+		return true, s.openFile(s.fileName, 0, func() {})
+	}
+
+	position, err := s.reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, fmt.Errorf("failed to seek file '%s': %w", s.fileName, err)
+	}
+
+	if current.Size() < position {
+		if !s.followName {
+			return false, fmt.Errorf("%w: '%s'", ErrTruncated, s.fileName)
+		}
+		_, err = s.reader.Seek(0, io.SeekStart)
+		return true, err
+	}
+	return false, nil
+}
+
+// Returns the segment following the current one (false if there is none yet)
+func (s *FileStream) nextSegment() (segment, bool, error) {
+	segments, err := listSegments(s.fileName)
+	if err != nil {
+		return segment{}, false, err
+	}
+
+	for _, next := range segments {
+		if next.offset > s.segmentOffset {
+			return next, true, nil
+		}
+	}
+	return segment{}, false, nil
+}
+
+// Returns the offset of the last n lines within the first size bytes of a file
+func lastLinesOffset(file io.ReaderAt, size int64, n int) (int64, error) {
+	if n <= 0 {
+		return size, nil
+	}
+
+	scanner := logframe.NewLineScanner(int64(n))
+	buffer := make([]byte, scanChunkSize)
+	for end := size; end > 0; {
+		start := end - scanChunkSize
+		if start < 0 {
+			start = 0
+		}
+
+		chunk := buffer[:end-start]
+		_, err := file.ReadAt(chunk, start)
+		if err != nil {
+			return 0, err
+		}
+
+		position, found := scanner.Scan(chunk)
+		if found {
+			return start + int64(position), nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// Blocks until there is a change of the file, or we're asked to stop waiting and/or reading.
+// Returns true if we've stopped waiting because of a given timeout.
+func (s *FileStream) waitForChanges(timeout <-chan time.Time) bool {
 	for {
 		select {
-		// Some write occurred, and we should see if we could read something
-		case event := <-s.watcher.Events:
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				return
+		// Some change occurred, and we should see if we could read something
+		case name := <-s.watcher.Events():
+			if name == "" {
+				return false // Anything could have changed
+			}
+			// Any change of the file itself could be a write, a truncation or the file being replaced
+			if !s.segmented && path.Clean(name) == path.Clean(s.fileName) {
+				return false
+			}
+			// For rotated files we watch the whole directory, so we only care about our own segments
+			if s.segmented && strings.HasPrefix(path.Clean(name), path.Clean(s.fileName)+".") {
+				return false
 			}
 		// We were asked to stop tailing the file
 		case <-s.logComplete:
-			return
+			return false
 		// We were asked to stop reading the file
 		case <-s.done:
-			return
+			return false
 		// The context has been cancelled and we should stop
 		case <-s.ctx.Done():
-			return
+			return false
+		// Nothing has happened for too long
+		case <-timeout:
+			return true
 		}
 	}
 }
@@ -129,14 +488,53 @@ func (s *FileStream) shouldStop() bool {
 // Read implements the io.Reader interface.
 // Returns the data available in the stream and blocks for more data if the stream is empty.
 func (s *FileStream) Read(buffer []byte) (int, error) {
+	var timeout <-chan time.Time
+	if s.readTimeout > 0 {
+		timer := time.NewTimer(s.readTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
 	for {
 		if s.shouldStop() {
 			return 0, io.EOF
 		}
 		readBytes, err := s.reader.Read(buffer)
 
+		// Move on to the next segment of a rotated file once we're done with the current one
+		if err == io.EOF && s.segmented {
+			next, found, nextErr := s.nextSegment()
+			if nextErr != nil {
+				return 0, nextErr
+			}
+			if found {
+				// Once the next segment exists, nothing else is written into the current one.
+				// But something could have been written since our last read, so we need to check once again.
+				readBytes, err = s.reader.Read(buffer)
+				if readBytes > 0 || err != io.EOF {
+					return readBytes, err
+				}
+
+				err = s.openSegment(next, 0)
+				if err != nil {
+					return 0, err
+				}
+				continue
+			}
+		}
+
 		// How we handle the EOF depends on the current tail mode
 		if err == io.EOF && s.TailEnabled() {
+			// Before waiting for more data, make sure the file is still there
+			if !s.segmented {
+				reopened, checkErr := s.checkFile()
+				if checkErr != nil {
+					return 0, checkErr
+				}
+				if reopened {
+					continue
+				}
+			}
 			err = nil
 		}
 
@@ -147,6 +545,8 @@ func (s *FileStream) Read(buffer []byte) (int, error) {
 		}
 
 		// Wait until we have more data to read or if we're stopped by context or a Close() call
-		s.waitForChanges()
+		if s.waitForChanges(timeout) {
+			return 0, fmt.Errorf("%w: no data in '%s' for %v", ErrTimeout, s.fileName, s.readTimeout)
+		}
 	}
 }
@@ -2,6 +2,7 @@ package filestream
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -12,165 +13,340 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// Returns the number of descriptors of the process referring to a given file
+func openDescriptors(fileName string) int {
+	entries, _ := os.ReadDir("/proc/self/fd")
+	count := 0
+	for _, entry := range entries {
+		target, err := os.Readlink("/proc/self/fd/" + entry.Name())
+		if err == nil && target == fileName {
+			count++
+		}
+	}
+	return count
+}
+
 func TestFileStream_MoreBytes(t *testing.T) {
 	var wg sync.WaitGroup
 	ctx := context.Background()
 	buffer := make([]byte, 100)
 
-	Convey("filestream.MoreBytes()", t, func() {
-		fileName := "/tmp/tail_test.log"
-		f, _ := os.Create(fileName)
-		initContent := "hello, world!\n"
-		f.WriteString(initContent)
-
-		stream, _ := New(ctx, fileName, true)
+	for _, backend := range []WatcherBackend{NotifyWatcher, PollingWatcher} {
+		Convey(fmt.Sprintf("filestream.MoreBytes() with the %s watcher", watcherNames[backend]), t, func() {
+			fileName := "/tmp/tail_test.log"
+			f, _ := os.Create(fileName)
+			initContent := "hello, world!\n"
+			f.WriteString(initContent)
 
-		Convey("Should return a full buffer when possible", func() {
-			f.WriteString(strings.Repeat("x", len(buffer)))
-			readBytes, err := stream.Read(buffer)
-			So(readBytes, ShouldEqual, len(buffer))
-			So(err, ShouldBeNil)
-		})
+			stream, _ := New(ctx, fileName, WithTail(true), WithWatcher(backend), WithPollInterval(testPollInterval))
 
-		Convey("Should return available bytes when reaches the end", func() {
-			readBytes, err := stream.Read(buffer)
-			So(err, ShouldBeNil)
-			So(readBytes, ShouldEqual, len(initContent))
-			So(string(buffer[:readBytes]), ShouldResemble, initContent)
-		})
+			Convey("Should return a full buffer when possible", func() {
+				f.WriteString(strings.Repeat("x", len(buffer)))
+				readBytes, err := stream.Read(buffer)
+				So(readBytes, ShouldEqual, len(buffer))
+				So(err, ShouldBeNil)
+			})
 
-		Convey("Should return more data when more data is added to the file", func() {
-			// Read the last 5 bytes and reach the end of the stream
-			stream.reader.Seek(-5, io.SeekEnd)
-			readBytes, err := stream.Read(buffer)
-			So(readBytes, ShouldEqual, 5)
-			So(err, ShouldBeNil)
+			Convey("Should return available bytes when reaches the end", func() {
+				readBytes, err := stream.Read(buffer)
+				So(err, ShouldBeNil)
+				So(readBytes, ShouldEqual, len(initContent))
+				So(string(buffer[:readBytes]), ShouldResemble, initContent)
+			})
 
-			// Add more data
-			moreContent := "banana"
-			f.WriteString(moreContent)
+			Convey("Should return more data when more data is added to the file", func() {
+				// Read the last 5 bytes and reach the end of the stream
+				stream.reader.Seek(-5, io.SeekEnd)
+				readBytes, err := stream.Read(buffer)
+				So(readBytes, ShouldEqual, 5)
+				So(err, ShouldBeNil)
 
-			// Should be able to consume it now
-			readBytes, err = stream.Read(buffer)
-			So(readBytes, ShouldEqual, len(moreContent))
-			So(err, ShouldBeNil)
-			So(string(buffer[:readBytes]), ShouldResemble, moreContent)
-		})
+				// Add more data
+				moreContent := "banana"
+				f.WriteString(moreContent)
 
-		Convey("When running in a tail mode", func() {
-			Convey("Should block and wait for more data when reaches the end", func() {
-				// read all data
-				stream.Read(buffer)
+				// Should be able to consume it now
+				readBytes, err = stream.Read(buffer)
+				So(readBytes, ShouldEqual, len(moreContent))
+				So(err, ShouldBeNil)
+				So(string(buffer[:readBytes]), ShouldResemble, moreContent)
+			})
 
-				// Write some content a second later
-				go func() {
-					time.Sleep(time.Second)
-					f.WriteString("yo")
-				}()
+			Convey("Should start reading from a position set by Seek()", func() {
+				position, err := stream.Seek(7, io.SeekStart)
+				So(err, ShouldBeNil)
+				So(position, ShouldEqual, 7)
 
 				readBytes, err := stream.Read(buffer)
-				So(readBytes, ShouldEqual, 2)
 				So(err, ShouldBeNil)
-				So(string(buffer[:readBytes]), ShouldResemble, "yo")
+				So(string(buffer[:readBytes]), ShouldResemble, "world!\n")
 			})
-		})
 
-		Convey("When running in a non-tail mode", func() {
-			stream, _ := New(ctx, fileName, false)
+			Convey("Should start reading from the last lines of the file", func() {
+				f.WriteString("one\ntwo\nthree\n")
+				_, err := stream.SeekLastLines(2)
+				So(err, ShouldBeNil)
 
-			Convey("Should return an EOF when reaches the end", func() {
-				// read all data
-				stream.Read(buffer)
+				readBytes, err := stream.Read(buffer)
+				So(err, ShouldBeNil)
+				So(string(buffer[:readBytes]), ShouldResemble, "two\nthree\n")
+			})
 
-				// Write some content a second later
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-					time.Sleep(time.Second)
-					f.WriteString("yo")
-				}()
+			Convey("Should start from the beginning of the file when it has less lines", func() {
+				position, err := stream.SeekLastLines(10)
+				So(err, ShouldBeNil)
+				So(position, ShouldEqual, 0)
+			})
+
+			Convey("Should start reading from the last bytes of the file", func() {
+				_, err := stream.SeekLastBytes(7)
+				So(err, ShouldBeNil)
 
 				readBytes, err := stream.Read(buffer)
-				So(readBytes, ShouldEqual, 0)
-				So(err, ShouldEqual, io.EOF)
+				So(err, ShouldBeNil)
+				So(string(buffer[:readBytes]), ShouldResemble, "world!\n")
+			})
 
-				// Wait for the async write operation to complete
-				wg.Wait()
+			Convey("When running in a tail mode", func() {
+				Convey("Should block and wait for more data when reaches the end", func() {
+					// read all data
+					stream.Read(buffer)
+
+					// Write some content a second later
+					go func() {
+						time.Sleep(time.Second)
+						f.WriteString("yo")
+					}()
+
+					readBytes, err := stream.Read(buffer)
+					So(readBytes, ShouldEqual, 2)
+					So(err, ShouldBeNil)
+					So(string(buffer[:readBytes]), ShouldResemble, "yo")
+				})
+
+				Convey("Should return an error when the file is truncated", func() {
+					stream.Read(buffer)
+					go func() {
+						time.Sleep(100 * time.Millisecond)
+						f.Truncate(0)
+					}()
+
+					_, err := stream.Read(buffer)
+					So(err, ShouldWrap, ErrTruncated)
+				})
+
+				Convey("Should return an error when the file is removed", func() {
+					stream.Read(buffer)
+					go func() {
+						time.Sleep(100 * time.Millisecond)
+						os.Remove(fileName)
+					}()
+
+					_, err := stream.Read(buffer)
+					So(err, ShouldWrap, ErrRemoved)
+				})
+
+				Convey("Should read a truncated file from the beginning when following it by name", func() {
+					stream.Close()
+					stream, _ = New(ctx, fileName, WithTail(true), WithFollowName(), WithWatcher(backend), WithPollInterval(testPollInterval))
+					stream.Read(buffer)
+					go func() {
+						time.Sleep(100 * time.Millisecond)
+						f.Truncate(0)
+						f.WriteAt([]byte("new"), 0)
+					}()
+
+					readBytes, err := stream.Read(buffer)
+					So(err, ShouldBeNil)
+					So(string(buffer[:readBytes]), ShouldResemble, "new")
+				})
+
+				Convey("Should switch to a new file with the same name when following it by name", func() {
+					stream.Close()
+					stream, _ = New(ctx, fileName, WithTail(true), WithFollowName(), WithWatcher(backend), WithPollInterval(testPollInterval))
+					stream.Read(buffer)
+					go func() {
+						time.Sleep(100 * time.Millisecond)
+						os.Rename(fileName, fileName+".1")
+						f.WriteString("old\n")
+						time.Sleep(100 * time.Millisecond)
+						os.WriteFile(fileName, []byte("new\n"), 0600)
+					}()
+
+					data := make([]byte, 8)
+					_, err := io.ReadFull(stream, data)
+					So(err, ShouldBeNil)
+					So(string(data), ShouldResemble, "old\nnew\n")
+					os.Remove(fileName + ".1")
+				})
 			})
-		})
 
-		Convey("When tailing is disabled while reading", func() {
-			stream.DisableTail()
+			Convey("When running in a non-tail mode", func() {
+				stream, _ := New(ctx, fileName, WithWatcher(backend), WithPollInterval(testPollInterval))
+
+				Convey("Should return an EOF when reaches the end", func() {
+					// read all data
+					stream.Read(buffer)
+
+					// Write some content a second later
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						time.Sleep(time.Second)
+						f.WriteString("yo")
+					}()
+
+					readBytes, err := stream.Read(buffer)
+					So(readBytes, ShouldEqual, 0)
+					So(err, ShouldEqual, io.EOF)
+
+					// Wait for the async write operation to complete
+					wg.Wait()
+				})
+			})
 
-			Convey("Should return an EOF when reaches the end", func() {
-				// read all data
+			Convey("When tailing is disabled while reading", func() {
+				stream.DisableTail()
+
+				Convey("Should return an EOF when reaches the end", func() {
+					// read all data
+					stream.Read(buffer)
+
+					// Write some content a second later
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						time.Sleep(time.Second)
+						f.WriteString("yo")
+					}()
+
+					readBytes, err := stream.Read(buffer)
+					So(readBytes, ShouldEqual, 0)
+					So(err, ShouldEqual, io.EOF)
+
+					// Wait for the async write operation to complete
+					wg.Wait()
+				})
+			})
+
+			Convey("Should return an EOF when cancelled via the context", func() {
+				// Create a stream that times out after a second
+				timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
+				defer cancel()
+				stream, _ := New(timeoutCtx, fileName, WithTail(true), WithWatcher(backend), WithPollInterval(testPollInterval))
+
+				// Read all data
+				stream.Read(buffer)
+
+				// Try to read more and block
+				_, err := stream.Read(buffer)
+
+				// Should return an EOF after being cancelled
+				So(err, ShouldEqual, io.EOF)
+			})
+
+			Convey("Should return an EOF when stopped by closing", func() {
+				// Read all data
 				stream.Read(buffer)
 
-				// Write some content a second later
-				wg.Add(1)
+				// Stop reading by closing the stream in a second
 				go func() {
-					defer wg.Done()
 					time.Sleep(time.Second)
-					f.WriteString("yo")
+					stream.Close()
 				}()
 
+				// Try to read more and block
+				_, err := stream.Read(buffer)
+
+				// Should return an EOF after being stopped
+				So(err, ShouldEqual, io.EOF)
+			})
+
+			Convey("Should return an EOF without reading any data if called after closing", func() {
+				stream.Close()
 				readBytes, err := stream.Read(buffer)
-				So(readBytes, ShouldEqual, 0)
 				So(err, ShouldEqual, io.EOF)
+				So(readBytes, ShouldEqual, 0)
+			})
 
-				// Wait for the async write operation to complete
-				wg.Wait()
+			Reset(func() {
+				stream.Close()
+				os.Remove(fileName)
 			})
 		})
+	}
 
-		Convey("Should return an EOF when cancelled via the context", func() {
-			// Create a stream that times out after a second
-			timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
-			defer cancel()
-			stream, _ := New(timeoutCtx, fileName, true)
+	Convey("lastLinesOffset()", t, func() {
+		Convey("Should scan files larger than a single chunk", func() {
+			content := "first\n" + strings.Repeat("x", scanChunkSize*2) + "\nlast\n"
+			offset, err := lastLinesOffset(strings.NewReader(content), int64(len(content)), 2)
+			So(err, ShouldBeNil)
+			So(offset, ShouldEqual, len("first\n"))
+		})
+	})
 
-			// Read all data
-			stream.Read(buffer)
+	Convey("filestream.New()", t, func() {
+		fileName := "/tmp/new_test.log"
+		os.WriteFile(fileName, []byte("hello, world!\n"), 0600)
 
-			// Try to read more and block
-			_, err := stream.Read(buffer)
+		Convey("Should start reading from a given offset", func() {
+			stream, err := New(ctx, fileName, WithOffset(7))
+			So(err, ShouldBeNil)
+			defer stream.Close()
 
-			// Should return an EOF after being cancelled
-			So(err, ShouldEqual, io.EOF)
+			data, _ := io.ReadAll(stream)
+			So(string(data), ShouldEqual, "world!\n")
 		})
 
-		Convey("Should return an EOF when stopped by closing", func() {
-			// Read all data
-			stream.Read(buffer)
-
-			// Stop reading by closing the stream in a second
-			go func() {
-				time.Sleep(time.Second)
-				stream.Close()
-			}()
+		Convey("Should stop watching the file when failing to seek it", func() {
+			_, err := New(ctx, fileName, WithOffset(-1))
+			So(err, ShouldNotBeNil)
 
-			// Try to read more and block
-			_, err := stream.Read(buffer)
+			hub := sharedHub(NotifyWatcher, 0)
+			hub.mu.Lock()
+			defer hub.mu.Unlock()
+			So(hub.refs[fileName], ShouldEqual, 0)
+		})
 
-			// Should return an EOF after being stopped
-			So(err, ShouldEqual, io.EOF)
+		Convey("Should close the file and the subscription when failing to watch the file", func() {
+			hub := sharedHub(NotifyWatcher, 0)
+			hub.mu.Lock()
+			subscribers := len(hub.subscribers)
+			hub.mu.Unlock()
+
+			create := newWatcher
+			newWatcher = func(backend WatcherBackend, pollInterval time.Duration, names ...string) (watcher, error) {
+				// The file itself is watched, but adding a missing directory fails
+				return create(backend, pollInterval, append(names, "/nonexistent/new_test")...)
+			}
+			defer func() { newWatcher = create }()
+
+			_, err := New(ctx, fileName, WithWatcher(NotifyWatcher))
+			So(err, ShouldNotBeNil)
+			So(openDescriptors(fileName), ShouldEqual, 0)
+
+			hub.mu.Lock()
+			defer hub.mu.Unlock()
+			So(hub.refs[fileName], ShouldEqual, 0)
+			So(hub.subscribers, ShouldHaveLength, subscribers)
 		})
 
-		Convey("Should return an EOF without reading any data if called after closing", func() {
-			stream.Close()
-			readBytes, err := stream.Read(buffer)
-			So(err, ShouldEqual, io.EOF)
-			So(readBytes, ShouldEqual, 0)
+		Convey("Should give up waiting for more data after the read timeout", func() {
+			stream, _ := New(ctx, fileName, WithTail(true), WithReadTimeout(100*time.Millisecond))
+			defer stream.Close()
+			stream.Read(buffer)
+
+			_, err := stream.Read(buffer)
+			So(err, ShouldWrap, ErrTimeout)
 		})
 
 		Reset(func() {
-			stream.Close()
 			os.Remove(fileName)
 		})
 	})
 
 	Convey("filestream.Close()", t, func() {
-		stream, _ := New(ctx, "/etc/hosts", true)
+		stream, _ := New(ctx, "/etc/hosts", WithTail(true))
 
 		Convey("Should not blow up when called twice and return no errors", func() {
 			So(stream.Close(), ShouldBeNil)
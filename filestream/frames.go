@@ -0,0 +1,49 @@
+package filestream
+
+import (
+	"io"
+	"time"
+
+	"teleport-exec/logframe"
+)
+
+// FrameReader reads a framed log (see logframe) from an underlying reader (e.g. a FileStream),
+// only returning the frames captured within a given time range
+type FrameReader struct {
+	frames *logframe.Reader
+	since  time.Time // Frames captured before this time are skipped
+	until  time.Time // Reading stops at the first frame captured after this time
+}
+
+// NewFrameReader creates a reader for the frames captured within [since, until] (a zero time leaves that end open).
+// The underlying reader must be positioned at the offset of the frame the given position points to.
+func NewFrameReader(r io.Reader, position logframe.Position, since time.Time, until time.Time) *FrameReader {
+	return &FrameReader{
+		frames: logframe.NewReaderAtPosition(r, position),
+		since:  since,
+		until:  until,
+	}
+}
+
+// ReadFrame returns the next frame within the time range. Returns io.EOF at the end of the log, or at the first frame
+// captured after the time range, since capture times never decrease along the log.
+func (r *FrameReader) ReadFrame() (logframe.Frame, error) {
+	for {
+		frame, err := r.frames.ReadFrame()
+		if err != nil {
+			return frame, err
+		}
+
+		if !r.until.IsZero() && frame.Time.After(r.until) {
+			return logframe.Frame{}, io.EOF
+		}
+		if !frame.Time.Before(r.since) {
+			return frame, nil
+		}
+	}
+}
+
+// Offset returns the position in the log right after the last frame read, including the frames skipped
+func (r *FrameReader) Offset() int64 {
+	return r.frames.Offset()
+}
@@ -0,0 +1,66 @@
+package filestream
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"teleport-exec/logframe"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Reads the data of all frames until an error
+func readFrameData(reader *FrameReader) ([]string, error) {
+	var data []string
+	for {
+		frame, err := reader.ReadFrame()
+		if err != nil {
+			return data, err
+		}
+		data = append(data, string(frame.Data))
+	}
+}
+
+func TestFrameReader(t *testing.T) {
+	Convey("FrameReader", t, func() {
+		buffer := &bytes.Buffer{}
+		writer := logframe.NewWriter(buffer)
+		for _, data := range []string{"one", "two", "three"} {
+			writer.WriteFrame(logframe.Stdout, []byte(data))
+			time.Sleep(10 * time.Millisecond)
+		}
+		log := buffer.Bytes()
+
+		var times []time.Time
+		frames := logframe.NewReader(bytes.NewReader(log))
+		for i := 0; i < 3; i++ {
+			frame, _ := frames.ReadFrame()
+			times = append(times, frame.Time)
+		}
+
+		Convey("Should return all frames without a time range", func() {
+			reader := NewFrameReader(bytes.NewReader(log), logframe.Position{}, time.Time{}, time.Time{})
+			data, err := readFrameData(reader)
+			So(err, ShouldEqual, io.EOF)
+			So(data, ShouldResemble, []string{"one", "two", "three"})
+			So(reader.Offset(), ShouldEqual, len(log))
+		})
+
+		Convey("Should only return the frames captured within the time range", func() {
+			reader := NewFrameReader(bytes.NewReader(log), logframe.Position{}, times[1], times[1])
+			data, err := readFrameData(reader)
+			So(err, ShouldEqual, io.EOF)
+			So(data, ShouldResemble, []string{"two"})
+		})
+
+		Convey("Should stop at the first frame captured after the time range", func() {
+			reader := NewFrameReader(bytes.NewReader(log), logframe.Position{}, time.Time{}, times[0])
+			data, err := readFrameData(reader)
+			So(err, ShouldEqual, io.EOF)
+			So(data, ShouldResemble, []string{"one"})
+			So(reader.Offset(), ShouldBeLessThan, len(log))
+		})
+	})
+}
@@ -0,0 +1,220 @@
+package filestream
+
+import (
+	"path"
+	"sync"
+	"time"
+)
+
+// Watchers shared by all streams within the process, one per backend (and poll interval)
+var (
+	hubsMu sync.Mutex
+	hubs   = make(map[hubKey]*watcherHub)
+)
+
+// Identifies a shared watcher
+type hubKey struct {
+	backend      WatcherBackend
+	pollInterval time.Duration
+}
+
+// Returns the shared watcher for a given backend, NotifyWatcher or PollingWatcher
+func sharedHub(backend WatcherBackend, pollInterval time.Duration) *watcherHub {
+	if backend != PollingWatcher {
+		pollInterval = 0
+	} else if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	hubsMu.Lock()
+	defer hubsMu.Unlock()
+
+	key := hubKey{backend: backend, pollInterval: pollInterval}
+	hub := hubs[key]
+	if hub == nil {
+		hub = newWatcherHub(func() (watcher, error) {
+			if backend == PollingWatcher {
+				return newPollingWatcher(pollInterval)
+			}
+			return newNotifyWatcher()
+		})
+		hubs[key] = hub
+	}
+	return hub
+}
+
+//-------------------------------------------------------------------------------------------------
+// watcherHub multiplexes the events of a single underlying watcher to many subscribers.
+// Each file or directory is watched once no matter how many subscribers are interested in it,
+// and the underlying watcher is closed once the last subscriber is gone.
+type watcherHub struct {
+	create func() (watcher, error) // Creates the underlying watcher
+
+	mu          sync.Mutex             // Protects access to the fields below
+	watcher     watcher                // Underlying watcher (nil if there are no subscribers)
+	done        chan bool              // Closed to stop dispatching the events of the underlying watcher
+	refs        map[string]int         // Number of subscribers watching each file or directory
+	subscribers map[*subscription]bool // All active subscribers
+}
+
+// Creates a hub using a given function to create the underlying watcher when needed
+func newWatcherHub(create func() (watcher, error)) *watcherHub {
+	return &watcherHub{
+		create:      create,
+		refs:        make(map[string]int),
+		subscribers: make(map[*subscription]bool),
+	}
+}
+
+// Returns a new subscriber watching a given list of files and directories
+func (h *watcherHub) subscribe(names ...string) (*subscription, error) {
+	h.mu.Lock()
+	if h.watcher == nil {
+		w, err := h.create()
+		if err != nil {
+			h.mu.Unlock()
+			return nil, err
+		}
+		h.watcher = w
+		h.done = make(chan bool)
+		go h.dispatch(w, h.done)
+	}
+
+	sub := &subscription{
+		hub:    h,
+		names:  make(map[string]bool),
+		events: make(chan string, 1),
+	}
+	h.subscribers[sub] = true
+	h.mu.Unlock()
+
+	err := addNames(sub, names...)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Forwards the events of the underlying watcher to the subscribers until asked to stop
+func (h *watcherHub) dispatch(w watcher, done chan bool) {
+	for {
+		select {
+		case name := <-w.Events():
+			h.notify(name)
+		case <-done:
+			return
+		}
+	}
+}
+
+// Notifies all subscribers watching a given file or the directory holding it
+func (h *watcherHub) notify(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		if name != "" && !sub.names[name] && !sub.names[path.Dir(name)] {
+			continue
+		}
+
+		// Never block on a slow subscriber. If it hasn't received the previous event yet,
+		// replace that event with one telling the subscriber to check everything.
+		select {
+		case sub.events <- name:
+		default:
+			select {
+			case <-sub.events:
+			default:
+			}
+			sub.events <- ""
+		}
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+// A subscriber of a watcher hub, implementing the watcher interface for a single stream
+type subscription struct {
+	hub    *watcherHub
+	names  map[string]bool // Files and directories watched by the subscriber (protected by the hub mutex)
+	events chan string
+	closed bool // Set to true when Close() is first called (protected by the hub mutex)
+}
+
+func (s *subscription) Add(name string) error {
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	name = path.Clean(name)
+	if s.names[name] {
+		return nil
+	}
+
+	if h.refs[name] == 0 {
+		err := h.watcher.Add(name)
+		if err != nil {
+			return err
+		}
+	}
+	h.refs[name]++
+	s.names[name] = true
+	return nil
+}
+
+func (s *subscription) Remove(name string) error {
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return s.remove(path.Clean(name))
+}
+
+func (s *subscription) Events() <-chan string {
+	return s.events
+}
+
+// Close stops watching all files of the subscriber, closing the underlying watcher if nobody else is using it
+func (s *subscription) Close() error {
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var err error
+	for name := range s.names {
+		if removeErr := s.remove(name); removeErr != nil {
+			err = removeErr
+		}
+	}
+
+	delete(h.subscribers, s)
+	if len(h.subscribers) == 0 {
+		close(h.done)
+		if closeErr := h.watcher.Close(); closeErr != nil {
+			err = closeErr
+		}
+		h.watcher = nil
+	}
+	return err
+}
+
+// Stops watching a given file, removing it from the underlying watcher if nobody else is watching it.
+// Must be called with the hub mutex held.
+func (s *subscription) remove(name string) error {
+	if !s.names[name] {
+		return nil
+	}
+	delete(s.names, name)
+
+	h := s.hub
+	h.refs[name]--
+	if h.refs[name] > 0 {
+		return nil
+	}
+	delete(h.refs, name)
+	return h.watcher.Remove(name)
+}
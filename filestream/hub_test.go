@@ -0,0 +1,101 @@
+package filestream
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// A watcher recording the watched files, with events sent by the test
+type fakeWatcher struct {
+	watched map[string]int
+	events  chan string
+	closed  bool
+}
+
+func (w *fakeWatcher) Add(name string) error {
+	w.watched[name]++
+	return nil
+}
+
+func (w *fakeWatcher) Remove(name string) error {
+	w.watched[name]--
+	if w.watched[name] == 0 {
+		delete(w.watched, name)
+	}
+	return nil
+}
+
+func (w *fakeWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *fakeWatcher) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestWatcherHub(t *testing.T) {
+	Convey("watcherHub", t, func() {
+		var created []*fakeWatcher
+		hub := newWatcherHub(func() (watcher, error) {
+			w := &fakeWatcher{watched: make(map[string]int), events: make(chan string)}
+			created = append(created, w)
+			return w, nil
+		})
+
+		first, err := hub.subscribe("/logs/one.log", "/logs")
+		So(err, ShouldBeNil)
+		second, err := hub.subscribe("/logs/one.log")
+		So(err, ShouldBeNil)
+
+		Convey("Should share a single underlying watcher", func() {
+			So(created, ShouldHaveLength, 1)
+			So(created[0].watched, ShouldResemble, map[string]int{"/logs/one.log": 1, "/logs": 1})
+		})
+
+		Convey("Should notify the subscribers watching a file", func() {
+			created[0].events <- "/logs/one.log"
+			So(<-first.Events(), ShouldEqual, "/logs/one.log")
+			So(<-second.Events(), ShouldEqual, "/logs/one.log")
+		})
+
+		Convey("Should notify the subscribers watching the directory of a file", func() {
+			created[0].events <- "/logs/two.log"
+			So(<-first.Events(), ShouldEqual, "/logs/two.log")
+			So(second.Events(), ShouldBeEmpty)
+		})
+
+		Convey("Should ask slow subscribers to check everything instead of blocking", func() {
+			created[0].events <- "/logs/one.log"
+			created[0].events <- "/logs/two.log"
+			created[0].events <- "/logs/one.log" // Makes sure the previous event has been dispatched
+			So(<-first.Events(), ShouldEqual, "")
+		})
+
+		Convey("Should keep watching a file until the last subscriber is closed", func() {
+			So(first.Close(), ShouldBeNil)
+			So(created[0].watched, ShouldResemble, map[string]int{"/logs/one.log": 1})
+			So(created[0].closed, ShouldBeFalse)
+
+			So(second.Close(), ShouldBeNil)
+			So(created[0].watched, ShouldBeEmpty)
+			So(created[0].closed, ShouldBeTrue)
+		})
+
+		Convey("Should create a new underlying watcher after the previous one has been closed", func() {
+			first.Close()
+			second.Close()
+
+			third, err := hub.subscribe("/logs/one.log")
+			So(err, ShouldBeNil)
+			defer third.Close()
+			So(created, ShouldHaveLength, 2)
+		})
+
+		Reset(func() {
+			first.Close()
+			second.Close()
+		})
+	})
+}
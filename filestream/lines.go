@@ -0,0 +1,197 @@
+package filestream
+
+import (
+	"bytes"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// Defaults used when splitting data into lines
+const (
+	DefaultMaxLineLength = 64 * 1024
+	DefaultIdleTimeout   = time.Second
+)
+
+// Size of the chunks read by the line reader
+const lineChunkSize = 32 * 1024
+
+// LineOptions controls how data is split into lines
+type LineOptions struct {
+	MaxLength   int           // Longer lines are split into several ones (DefaultMaxLineLength if 0)
+	IdleTimeout time.Duration // A partial line is returned if no more data arrives for this long (DefaultIdleTimeout if 0)
+}
+
+// Returns the options with zero values replaced by the defaults
+func (o LineOptions) withDefaults() LineOptions {
+	if o.MaxLength <= 0 {
+		o.MaxLength = DefaultMaxLineLength
+	}
+	if o.IdleTimeout <= 0 {
+		o.IdleTimeout = DefaultIdleTimeout
+	}
+	return o
+}
+
+//-------------------------------------------------------------------------------------------------
+// LineBuffer accumulates data and splits it into lines, never splitting UTF-8 sequences
+type LineBuffer struct {
+	maxLength int
+	data      []byte
+}
+
+// NewLineBuffer creates a buffer splitting lines longer than maxLength (DefaultMaxLineLength if 0)
+func NewLineBuffer(maxLength int) *LineBuffer {
+	return &LineBuffer{maxLength: LineOptions{MaxLength: maxLength}.withDefaults().MaxLength}
+}
+
+// Write adds data to the buffer
+func (b *LineBuffer) Write(data []byte) {
+	b.data = append(b.data, data...)
+}
+
+// Len returns the amount of buffered data
+func (b *LineBuffer) Len() int {
+	return len(b.data)
+}
+
+// Next returns the next complete line (including the newline) or the beginning of a line longer than
+// the maximum length. Returns false if there is no complete line in the buffer.
+func (b *LineBuffer) Next() ([]byte, bool) {
+	end := bytes.IndexByte(b.data, '\n') + 1
+	if end == 0 || end > b.maxLength {
+		if len(b.data) < b.maxLength {
+			return nil, false
+		}
+		end = runeBoundary(b.data, b.maxLength)
+	}
+	return b.take(end), true
+}
+
+// Flush returns all buffered data except an incomplete UTF-8 sequence at the end,
+// which is kept until the rest of it arrives
+func (b *LineBuffer) Flush() []byte {
+	return b.take(runeBoundary(b.data, len(b.data)))
+}
+
+// Drain returns all buffered data
+func (b *LineBuffer) Drain() []byte {
+	return b.take(len(b.data))
+}
+
+// Removes and returns the first n bytes of the buffer
+func (b *LineBuffer) take(n int) []byte {
+	line := make([]byte, n)
+	copy(line, b.data)
+	b.data = b.data[n:]
+	return line
+}
+
+// Returns the largest position not greater than end, which doesn't split a UTF-8 sequence
+func runeBoundary(data []byte, end int) int {
+	for i := end; i > 0 && i > end-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i-1]) {
+			if utf8.FullRune(data[i-1 : end]) {
+				return end
+			}
+			return i - 1
+		}
+	}
+	return end // Not valid UTF-8 anyway, so just split it
+}
+
+//-------------------------------------------------------------------------------------------------
+// LineReader reads complete lines from an underlying reader (e.g. a FileStream).
+// Partial lines are returned if no more data arrives for a while, or when the reader hits an error.
+type LineReader struct {
+	buffer      *LineBuffer
+	idleTimeout time.Duration
+	chunks      chan readResult // Data read by the background reader goroutine
+	err         error           // Error which stopped the background reader
+	done        chan bool       // Closed to stop the background reader
+}
+
+// Result of reading a chunk of data from the underlying reader
+type readResult struct {
+	data []byte
+	err  error
+}
+
+// NewLineReader creates a line reader for a given reader, starting a goroutine reading it in the background
+func NewLineReader(reader io.Reader, options LineOptions) *LineReader {
+	options = options.withDefaults()
+	r := &LineReader{
+		buffer:      NewLineBuffer(options.MaxLength),
+		idleTimeout: options.IdleTimeout,
+		chunks:      make(chan readResult),
+		done:        make(chan bool),
+	}
+
+	go r.readChunks(reader)
+	return r
+}
+
+// ReadLine returns the next line. Returns the error of the underlying reader (e.g. io.EOF) when there are no more lines.
+func (r *LineReader) ReadLine() ([]byte, error) {
+	for {
+		line, ok := r.buffer.Next()
+		if ok {
+			return line, nil
+		}
+
+		if r.err != nil {
+			if r.buffer.Len() > 0 {
+				return r.buffer.Drain(), nil
+			}
+			return nil, r.err
+		}
+
+		if r.buffer.Len() == 0 {
+			r.receive(<-r.chunks)
+			continue
+		}
+
+		// There is a partial line, so we shouldn't wait for the rest of it forever
+		timer := time.NewTimer(r.idleTimeout)
+		select {
+		case result := <-r.chunks:
+			timer.Stop()
+			r.receive(result)
+		case <-timer.C:
+			line := r.buffer.Flush()
+			if len(line) > 0 {
+				return line, nil
+			}
+		}
+	}
+}
+
+// Close stops the background reader. The underlying reader needs to be closed separately,
+// if the background reader is blocked reading it.
+func (r *LineReader) Close() {
+	close(r.done)
+}
+
+// Reads chunks of data in the background, until the underlying reader returns an error
+func (r *LineReader) readChunks(reader io.Reader) {
+	for {
+		buffer := make([]byte, lineChunkSize)
+		readBytes, err := reader.Read(buffer)
+
+		select {
+		case r.chunks <- readResult{data: buffer[:readBytes], err: err}:
+		case <-r.done:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Adds the result of a read to the buffer
+func (r *LineReader) receive(result readResult) {
+	r.buffer.Write(result.data)
+	r.err = result.err
+}
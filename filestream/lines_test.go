@@ -0,0 +1,122 @@
+package filestream
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLineBuffer(t *testing.T) {
+	Convey("LineBuffer", t, func() {
+		buffer := NewLineBuffer(8)
+
+		Convey("Should return complete lines only", func() {
+			buffer.Write([]byte("one\ntw"))
+			line, ok := buffer.Next()
+			So(ok, ShouldBeTrue)
+			So(string(line), ShouldEqual, "one\n")
+
+			_, ok = buffer.Next()
+			So(ok, ShouldBeFalse)
+
+			buffer.Write([]byte("o\n"))
+			line, _ = buffer.Next()
+			So(string(line), ShouldEqual, "two\n")
+		})
+
+		Convey("Should split lines longer than the maximum length", func() {
+			buffer.Write([]byte("0123456789\n"))
+			line, _ := buffer.Next()
+			So(string(line), ShouldEqual, "01234567")
+			line, _ = buffer.Next()
+			So(string(line), ShouldEqual, "89\n")
+		})
+
+		Convey("Should not split UTF-8 sequences", func() {
+			buffer.Write([]byte("012345€\n")) // The euro sign takes 3 bytes
+			line, _ := buffer.Next()
+			So(string(line), ShouldEqual, "012345")
+			line, _ = buffer.Next()
+			So(string(line), ShouldEqual, "€\n")
+		})
+
+		Convey("Should keep an incomplete UTF-8 sequence when flushing", func() {
+			euro := []byte("€")
+			buffer.Write(append([]byte("ab"), euro[:2]...))
+			So(string(buffer.Flush()), ShouldEqual, "ab")
+			So(buffer.Len(), ShouldEqual, 2)
+
+			buffer.Write(euro[2:])
+			So(string(buffer.Flush()), ShouldEqual, "€")
+		})
+
+		Convey("Should return everything when drained", func() {
+			buffer.Write([]byte("abc"))
+			So(string(buffer.Drain()), ShouldEqual, "abc")
+			So(buffer.Len(), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestLineReader(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("LineReader", t, func() {
+		Convey("Should return whole lines and the partial last line at the end", func() {
+			reader := NewLineReader(strings.NewReader("one\ntwo\nthree"), LineOptions{})
+			defer reader.Close()
+
+			lines := []string{}
+			for {
+				line, err := reader.ReadLine()
+				if err == io.EOF {
+					break
+				}
+				So(err, ShouldBeNil)
+				lines = append(lines, string(line))
+			}
+			So(lines, ShouldResemble, []string{"one\n", "two\n", "three"})
+		})
+
+		Convey("When tailing a file", func() {
+			fileName := "/tmp/lines_test.log"
+			f, _ := os.Create(fileName)
+			stream, _ := New(ctx, fileName, WithTail(true))
+			reader := NewLineReader(stream, LineOptions{IdleTimeout: 200 * time.Millisecond})
+
+			Convey("Should wait for the rest of a line", func() {
+				f.WriteString("hel")
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					f.WriteString("lo\n")
+				}()
+
+				line, err := reader.ReadLine()
+				So(err, ShouldBeNil)
+				So(string(line), ShouldEqual, "hello\n")
+			})
+
+			Convey("Should flush a partial line when no more data arrives", func() {
+				f.WriteString("prompt> ")
+				start := time.Now()
+
+				line, err := reader.ReadLine()
+				So(err, ShouldBeNil)
+				So(string(line), ShouldEqual, "prompt> ")
+				So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 200*time.Millisecond)
+			})
+
+			Reset(func() {
+				stream.Close()
+				reader.Close()
+				f.Close()
+				os.Remove(fileName)
+			})
+		})
+	})
+}
@@ -0,0 +1,263 @@
+package filestream
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A rotated file is stored as a sequence of segments named <fileName>.<offset>, where offset is the position
+// of the first byte of the segment within the whole file. This way positions within the file stay the same,
+// even when the oldest segments are removed.
+
+// SegmentName returns the name of a segment starting at a given offset
+func SegmentName(fileName string, offset int64) string {
+	return fileName + "." + strconv.FormatInt(offset, 10)
+}
+
+// RemoveSegments removes all segments of a given file
+func RemoveSegments(fileName string) error {
+	segments, err := listSegments(fileName)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		err = os.Remove(segment.name)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// A segment of a file
+type segment struct {
+	name   string
+	offset int64 // Position of the first byte of the segment within the whole file
+	size   int64
+}
+
+// Returns existing segments of a given file, ordered by their offsets
+func listSegments(fileName string) ([]segment, error) {
+	entries, err := os.ReadDir(path.Dir(fileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments of '%s': %w", fileName, err)
+	}
+
+	prefix := path.Base(fileName) + "."
+	var segments []segment
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+
+		offset, err := strconv.ParseInt(strings.TrimPrefix(entry.Name(), prefix), 10, 64)
+		if err != nil || offset < 0 {
+			continue // Not a segment
+		}
+
+		segments = append(segments, segment{name: path.Join(path.Dir(fileName), entry.Name()), offset: offset})
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].offset < segments[j].offset })
+	return segments, nil
+}
+
+// Returns the segment containing a given offset (the first segment if the offset has been removed already)
+func findSegment(segments []segment, offset int64) segment {
+	found := segments[0]
+	for _, segment := range segments {
+		if segment.offset <= offset {
+			found = segment
+		}
+	}
+	return found
+}
+
+//-------------------------------------------------------------------------------------------------
+// Segments provides random access to the retained segments of a rotated file
+type Segments struct {
+	files   []*os.File
+	offsets []int64
+	start   int64 // Offset of the first retained byte
+	end     int64 // Offset right after the last byte
+}
+
+// OpenSegments opens all segments of a given file, the segments are not affected by rotation after that
+func OpenSegments(fileName string) (*Segments, error) {
+	segments, err := listSegments(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("no segments found for '%s': %w", fileName, os.ErrNotExist)
+	}
+
+	s := &Segments{start: segments[0].offset}
+	for _, segment := range segments {
+		file, err := os.Open(segment.name)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to open segment '%s': %w", segment.name, err)
+		}
+		s.files = append(s.files, file)
+		s.offsets = append(s.offsets, segment.offset)
+
+		info, err := file.Stat()
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to stat segment '%s': %w", segment.name, err)
+		}
+		s.end = segment.offset + info.Size()
+	}
+	return s, nil
+}
+
+// Start returns the offset of the first retained byte
+func (s *Segments) Start() int64 {
+	return s.start
+}
+
+// End returns the offset right after the last byte
+func (s *Segments) End() int64 {
+	return s.end
+}
+
+// ReadAt implements the io.ReaderAt interface using offsets within the whole file
+func (s *Segments) ReadAt(buffer []byte, offset int64) (int, error) {
+	if offset < s.start {
+		return 0, fmt.Errorf("offset %d has been removed by rotation", offset)
+	}
+
+	total := 0
+	for i := range s.files {
+		// Skip the segments ending before the current position
+		position := offset + int64(total)
+		if i+1 < len(s.files) && s.offsets[i+1] <= position {
+			continue
+		}
+
+		readBytes, err := s.files[i].ReadAt(buffer[total:], position-s.offsets[i])
+		total += readBytes
+		if err != nil && err != io.EOF {
+			return total, err
+		}
+		if total == len(buffer) {
+			return total, nil
+		}
+	}
+	return total, io.EOF
+}
+
+// Close closes all segments
+func (s *Segments) Close() error {
+	var err error
+	for _, file := range s.files {
+		if closeErr := file.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+//-------------------------------------------------------------------------------------------------
+// SegmentWriter writes a file as a sequence of segments, starting a new segment when the current one
+// reaches the maximum size and removing the oldest segments when the total size exceeds the limit.
+// A single write is never split between segments.
+type SegmentWriter struct {
+	fileName    string
+	segmentSize int64     // Size at which a new segment is started (never rotated if 0)
+	maxSize     int64     // Maximum total size of the retained segments (unlimited if 0)
+	file        *os.File  // Current segment
+	segments    []segment // All retained segments including the current one
+}
+
+// NewSegmentWriter creates a writer starting the first segment of a given file
+func NewSegmentWriter(fileName string, segmentSize int64, maxSize int64) (*SegmentWriter, error) {
+	w := &SegmentWriter{
+		fileName:    fileName,
+		segmentSize: segmentSize,
+		maxSize:     maxSize,
+	}
+
+	err := w.startSegment(0)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write implements the io.Writer interface, writing data into the current segment
+func (w *SegmentWriter) Write(data []byte) (int, error) {
+	current := &w.segments[len(w.segments)-1]
+	if w.segmentSize > 0 && current.size > 0 && current.size+int64(len(data)) > w.segmentSize {
+		err := w.rotate()
+		if err != nil {
+			return 0, err
+		}
+		current = &w.segments[len(w.segments)-1]
+	}
+
+	written, err := w.file.Write(data)
+	current.size += int64(written)
+	return written, err
+}
+
+// Close closes the current segment
+func (w *SegmentWriter) Close() error {
+	return w.file.Close()
+}
+
+// Starts a new segment and removes the oldest ones exceeding the size limit
+func (w *SegmentWriter) rotate() error {
+	current := w.segments[len(w.segments)-1]
+	err := w.file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close segment '%s': %w", current.name, err)
+	}
+
+	err = w.startSegment(current.offset + current.size)
+	if err != nil {
+		return err
+	}
+
+	if w.maxSize <= 0 {
+		return nil
+	}
+
+	// Keep the current segment (which is empty now) and as many previous ones as the limit allows
+	total := int64(0)
+	for i := len(w.segments) - 2; i >= 0; i-- {
+		total += w.segments[i].size
+		if total > w.maxSize-w.segmentSize {
+			for _, segment := range w.segments[:i+1] {
+				err = os.Remove(segment.name)
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("failed to remove segment '%s': %w", segment.name, err)
+				}
+			}
+			w.segments = w.segments[i+1:]
+			break
+		}
+	}
+	return nil
+}
+
+// Creates a new segment starting at a given offset
+func (w *SegmentWriter) startSegment(offset int64) error {
+	name := SegmentName(w.fileName, offset)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create segment '%s': %w", name, err)
+	}
+
+	w.file = file
+	w.segments = append(w.segments, segment{name: name, offset: offset})
+	return nil
+}
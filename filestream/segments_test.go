@@ -0,0 +1,188 @@
+package filestream
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Returns the offsets of the existing segments of a file
+func segmentOffsets(fileName string) []int64 {
+	segments, _ := listSegments(fileName)
+	offsets := []int64{}
+	for _, segment := range segments {
+		offsets = append(offsets, segment.offset)
+	}
+	return offsets
+}
+
+func TestSegmentWriter(t *testing.T) {
+	Convey("SegmentWriter", t, func() {
+		dir, _ := os.MkdirTemp("", "segments_test")
+		fileName := path.Join(dir, "test.log")
+
+		Convey("Should start a new segment when the current one is full", func() {
+			writer, err := NewSegmentWriter(fileName, 10, 0)
+			So(err, ShouldBeNil)
+			writer.Write([]byte("12345678"))
+			writer.Write([]byte("abcd"))
+			writer.Close()
+
+			So(segmentOffsets(fileName), ShouldResemble, []int64{0, 8})
+			data, _ := os.ReadFile(SegmentName(fileName, 8))
+			So(string(data), ShouldEqual, "abcd")
+		})
+
+		Convey("Should never split a write between segments", func() {
+			writer, _ := NewSegmentWriter(fileName, 10, 0)
+			writer.Write([]byte("0123456789abcde"))
+			writer.Close()
+
+			So(segmentOffsets(fileName), ShouldResemble, []int64{0})
+		})
+
+		Convey("Should remove the oldest segments beyond the maximum size", func() {
+			writer, _ := NewSegmentWriter(fileName, 4, 8)
+			for _, data := range []string{"aaaa", "bbbb", "cccc", "dddd"} {
+				writer.Write([]byte(data))
+			}
+			writer.Close()
+
+			So(segmentOffsets(fileName), ShouldResemble, []int64{8, 12})
+		})
+
+		Convey("Should remove all segments with RemoveSegments()", func() {
+			writer, _ := NewSegmentWriter(fileName, 4, 0)
+			writer.Write([]byte("aaaa"))
+			writer.Write([]byte("bbbb"))
+			writer.Close()
+
+			So(RemoveSegments(fileName), ShouldBeNil)
+			So(segmentOffsets(fileName), ShouldBeEmpty)
+		})
+
+		Reset(func() {
+			os.RemoveAll(dir)
+		})
+	})
+}
+
+func TestSegments(t *testing.T) {
+	Convey("Segments", t, func() {
+		dir, _ := os.MkdirTemp("", "segments_test")
+		fileName := path.Join(dir, "test.log")
+		writer, _ := NewSegmentWriter(fileName, 4, 8)
+		for _, data := range []string{"aaaa", "bbbb", "cccc", "dddd"} {
+			writer.Write([]byte(data))
+		}
+		writer.Close()
+
+		segments, err := OpenSegments(fileName)
+		So(err, ShouldBeNil)
+
+		Convey("Should report the range of retained offsets", func() {
+			So(segments.Start(), ShouldEqual, 8)
+			So(segments.End(), ShouldEqual, 16)
+		})
+
+		Convey("Should read across segments", func() {
+			buffer := make([]byte, 6)
+			readBytes, err := segments.ReadAt(buffer, 10)
+			So(err, ShouldBeNil)
+			So(string(buffer[:readBytes]), ShouldEqual, "ccdddd")
+		})
+
+		Convey("Should return io.EOF when reading past the end", func() {
+			buffer := make([]byte, 4)
+			readBytes, err := segments.ReadAt(buffer, 14)
+			So(err, ShouldEqual, io.EOF)
+			So(string(buffer[:readBytes]), ShouldEqual, "dd")
+		})
+
+		Convey("Should fail to read removed offsets", func() {
+			_, err := segments.ReadAt(make([]byte, 4), 0)
+			So(err, ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			segments.Close()
+			os.RemoveAll(dir)
+		})
+	})
+}
+
+func TestFileStream_Segmented(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Segmented FileStream", t, func() {
+		dir, _ := os.MkdirTemp("", "segments_test")
+		fileName := path.Join(dir, "test.log")
+		writer, _ := NewSegmentWriter(fileName, 4, 8)
+		writer.Write([]byte("aaaa"))
+		writer.Write([]byte("bbbb"))
+		writer.Write([]byte("cccc"))
+
+		Convey("Should read all retained segments", func() {
+			stream, err := New(ctx, fileName, WithSegments())
+			So(err, ShouldBeNil)
+			defer stream.Close()
+
+			data, err := io.ReadAll(stream)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "bbbbcccc")
+		})
+
+		Convey("Should follow new segments when tailing", func() {
+			stream, _ := New(ctx, fileName, WithSegments(), WithTail(true))
+			defer stream.Close()
+			stream.Seek(0, io.SeekEnd)
+
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				writer.Write([]byte("dddd"))
+				writer.Write([]byte("eeee"))
+			}()
+
+			data := make([]byte, 8)
+			_, err := io.ReadFull(stream, data)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "ddddeeee")
+		})
+
+		Convey("Should seek using offsets within the whole file", func() {
+			stream, _ := New(ctx, fileName, WithSegments())
+			defer stream.Close()
+
+			offset, err := stream.Seek(10, io.SeekStart)
+			So(err, ShouldBeNil)
+			So(offset, ShouldEqual, 10)
+			data, _ := io.ReadAll(stream)
+			So(string(data), ShouldEqual, "cc")
+
+			offset, err = stream.Seek(0, io.SeekStart)
+			So(err, ShouldBeNil)
+			So(offset, ShouldEqual, 4) // The first segment has been removed
+		})
+
+		Convey("Should seek to the last bytes across segments", func() {
+			stream, _ := New(ctx, fileName, WithSegments())
+			defer stream.Close()
+
+			offset, err := stream.SeekLastBytes(6)
+			So(err, ShouldBeNil)
+			So(offset, ShouldEqual, 6)
+			data, _ := io.ReadAll(stream)
+			So(string(data), ShouldEqual, "bbcccc")
+		})
+
+		Reset(func() {
+			writer.Close()
+			os.RemoveAll(dir)
+		})
+	})
+}
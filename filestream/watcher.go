@@ -0,0 +1,270 @@
+package filestream
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultPollInterval is used by the polling watcher unless configured otherwise
+const DefaultPollInterval = 250 * time.Millisecond
+
+// WatcherBackend selects how a FileStream learns about file changes
+type WatcherBackend int
+
+const (
+	AutoWatcher    WatcherBackend = iota // Use inotify, falling back to polling if inotify is not available
+	NotifyWatcher                        // Use inotify only
+	PollingWatcher                       // Poll the files with stat() periodically
+)
+
+// watcher notifies about changes of files and directories.
+// Events carries the names of changed files (an empty name means that anything could have changed).
+type watcher interface {
+	Add(name string) error
+	Remove(name string) error
+	Events() <-chan string
+	Close() error
+}
+
+// Creates a watcher for a given backend, watching a given list of files and directories.
+// The underlying inotify instance or poller is shared with all other streams using the same backend (see watcherHub).
+// It is a variable, so that tests could make it fail.
+var newWatcher = func(backend WatcherBackend, pollInterval time.Duration, names ...string) (watcher, error) {
+	if backend != PollingWatcher {
+		w, err := sharedHub(NotifyWatcher, 0).subscribe(names...)
+		if err == nil || backend == NotifyWatcher {
+			return w, err
+		}
+	}
+	return sharedHub(PollingWatcher, pollInterval).subscribe(names...)
+}
+
+// Adds a list of files and directories to a watcher, closing it on errors
+func addNames(w watcher, names ...string) error {
+	for _, name := range names {
+		err := w.Add(name)
+		if err != nil {
+			w.Close()
+			return fmt.Errorf("failed to add '%s' to the watcher: %w", name, err)
+		}
+	}
+	return nil
+}
+
+//-------------------------------------------------------------------------------------------------
+// A watcher based on inotify (see fsnotify)
+type notifyWatcher struct {
+	watcher *fsnotify.Watcher
+	events  chan string
+	done    chan bool
+}
+
+func newNotifyWatcher() (*notifyWatcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start a watcher: %w", err)
+	}
+
+	w := &notifyWatcher{
+		watcher: fsWatcher,
+		events:  make(chan string),
+		done:    make(chan bool),
+	}
+	go w.forwardEvents()
+	return w, nil
+}
+
+func (w *notifyWatcher) Add(name string) error {
+	return w.watcher.Add(name)
+}
+
+func (w *notifyWatcher) Remove(name string) error {
+	return w.watcher.Remove(name)
+}
+
+func (w *notifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *notifyWatcher) Close() error {
+	close(w.done)
+	return w.watcher.Close()
+}
+
+// Forwards the names of changed files until the watcher is closed
+func (w *notifyWatcher) forwardEvents() {
+	for {
+		name := ""
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			name = event.Name
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events could have been lost (e.g. the event queue has overflown), so let the readers check everything
+		case <-w.done:
+			return
+		}
+
+		select {
+		case w.events <- name:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+// A watcher polling files with stat(), for filesystems and environments where inotify is not available
+type pollingWatcher struct {
+	interval time.Duration
+	events   chan string
+	done     chan bool
+
+	mu    sync.Mutex             // Protects access to the watched files
+	names map[string]bool        // Watched files and directories
+	files map[string]os.FileInfo // Last known state of the watched files and the files within watched directories
+}
+
+func newPollingWatcher(interval time.Duration) (*pollingWatcher, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	w := &pollingWatcher{
+		interval: interval,
+		events:   make(chan string),
+		done:     make(chan bool),
+		names:    make(map[string]bool),
+		files:    make(map[string]os.FileInfo),
+	}
+	go w.poll()
+	return w, nil
+}
+
+func (w *pollingWatcher) Add(name string) error {
+	files, err := statFiles(name)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.names[name] = true
+	for file, info := range files {
+		w.files[file] = info
+	}
+	return nil
+}
+
+func (w *pollingWatcher) Remove(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.names, name)
+	for file := range w.files {
+		if file == name || path.Dir(file) == name {
+			delete(w.files, file)
+		}
+	}
+	return nil
+}
+
+func (w *pollingWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *pollingWatcher) Close() error {
+	close(w.done)
+	return nil
+}
+
+// Periodically compares the state of the watched files with the last known one, until the watcher is closed
+func (w *pollingWatcher) poll() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.done:
+			return
+		}
+
+		for _, name := range w.changes() {
+			select {
+			case w.events <- name:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// Returns the names of files changed since the last check, updating their known state
+func (w *pollingWatcher) changes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	files := make(map[string]os.FileInfo)
+	for name := range w.names {
+		found, err := statFiles(name)
+		if err != nil {
+			continue // Removed files are reported below
+		}
+		for file, info := range found {
+			files[file] = info
+		}
+	}
+
+	var changed []string
+	for file, info := range files {
+		old, ok := w.files[file]
+		if !ok || !os.SameFile(old, info) || old.Size() != info.Size() || !old.ModTime().Equal(info.ModTime()) {
+			changed = append(changed, file)
+		}
+	}
+	for file := range w.files {
+		if _, ok := files[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+
+	w.files = files
+	return changed
+}
+
+// Returns the state of a file, or of a directory and all files within it
+func statFiles(name string) (map[string]os.FileInfo, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]os.FileInfo{name: info}
+	if !info.IsDir() {
+		return files, nil
+	}
+
+	entries, err := os.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue // The file has just been removed
+		}
+		files[path.Join(name, entry.Name())] = info
+	}
+	return files, nil
+}
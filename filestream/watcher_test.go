@@ -0,0 +1,77 @@
+package filestream
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Poll interval used by tests, short enough to keep them fast
+const testPollInterval = 50 * time.Millisecond
+
+// Names of the watcher backends used in test descriptions
+var watcherNames = map[WatcherBackend]string{
+	NotifyWatcher:  "inotify",
+	PollingWatcher: "polling",
+}
+
+// Waits for an event about a given file (or any file), skipping events about other files
+func waitForEvent(w watcher, name string) bool {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-w.Events():
+			if event == name || event == "" {
+				return true
+			}
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	for _, backend := range []WatcherBackend{NotifyWatcher, PollingWatcher} {
+		Convey(fmt.Sprintf("Watcher with the %s backend", watcherNames[backend]), t, func() {
+			dir, _ := os.MkdirTemp("", "watcher_test")
+			fileName := path.Join(dir, "test.log")
+			os.WriteFile(fileName, []byte("hello"), 0600)
+
+			w, err := newWatcher(backend, testPollInterval, fileName, dir)
+			So(err, ShouldBeNil)
+
+			Convey("Should report writes to a watched file", func() {
+				f, _ := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND, 0600)
+				defer f.Close()
+				f.WriteString(", world")
+
+				So(waitForEvent(w, fileName), ShouldBeTrue)
+			})
+
+			Convey("Should report new files within a watched directory", func() {
+				os.WriteFile(path.Join(dir, "new.log"), []byte("new"), 0600)
+
+				So(waitForEvent(w, path.Join(dir, "new.log")), ShouldBeTrue)
+			})
+
+			Convey("Should report removed files", func() {
+				os.Remove(fileName)
+
+				So(waitForEvent(w, fileName), ShouldBeTrue)
+			})
+
+			Convey("Should fail to watch a missing file", func() {
+				So(w.Add(path.Join(dir, "missing.log")), ShouldNotBeNil)
+			})
+
+			Reset(func() {
+				w.Close()
+				os.RemoveAll(dir)
+			})
+		})
+	}
+}
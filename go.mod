@@ -1,8 +1,9 @@
 module teleport-exec
 
-go 1.17
+go 1.20
 
 require (
+	github.com/google/uuid v1.3.0
 	github.com/smartystreets/goconvey v1.7.2
 	google.golang.org/grpc v1.43.0
 )
@@ -17,7 +18,7 @@ require (
 	github.com/fsnotify/fsnotify v1.5.1
 	github.com/golang/protobuf v1.4.3 // indirect
 	golang.org/x/net v0.0.0-20201021035429-f5854403a974 // indirect
-	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
+	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c
 	golang.org/x/text v0.3.3 // indirect
 	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
 	google.golang.org/protobuf v1.25.0
@@ -0,0 +1,212 @@
+// Package logframe implements a simple framed log format used to store command output
+// while preserving the identity of the stream (stdout or stderr) each chunk came from.
+//
+// Each frame consists of a header (1 byte stream id + 8 bytes big-endian capture time in
+// nanoseconds since the Unix epoch + 4 bytes big-endian data length), followed by the data
+// and a trailer (4 bytes big-endian data length again), which allows scanning the log backwards.
+//
+// Capture times never decrease along the log (even if the wall clock is set back), so readers
+// looking for a time range could stop at the first frame past it.
+package logframe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Stream identifies where the output came from. The zero value is used to match all streams.
+type Stream byte
+
+const (
+	Stdout Stream = 1
+	Stderr Stream = 2
+)
+
+// Matches returns true if a frame from a given stream passes the filter
+func (s Stream) Matches(stream Stream) bool {
+	return s == 0 || s == stream
+}
+
+func (s Stream) String() string {
+	switch s {
+	case Stdout:
+		return "stdout"
+	case Stderr:
+		return "stderr"
+	default:
+		return fmt.Sprintf("stream(%d)", byte(s))
+	}
+}
+
+// Size of the frame header, the trailer and both of them together
+const (
+	headerSize    = 13
+	trailerSize   = 4
+	frameOverhead = headerSize + trailerSize
+)
+
+// MaxFrameSize is the largest amount of data a single frame could carry.
+// Frames are kept small, so that a frame could always be sent to a client as a whole.
+const MaxFrameSize = 64 * 1024
+
+// ErrCorrupted is returned by the reader when it encounters an invalid frame
+var ErrCorrupted = errors.New("corrupted log frame")
+
+// Frame is a single chunk of output written into a given stream
+type Frame struct {
+	Stream Stream
+	Time   time.Time // When the output has been captured
+	Data   []byte
+}
+
+//-------------------------------------------------------------------------------------------------
+// Writer writes frames into an underlying writer, it is safe for concurrent use
+type Writer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	now  func() time.Time // Returns the capture time for new frames
+	last int64            // Capture time of the last frame written, in nanoseconds since the Unix epoch
+}
+
+// NewWriter creates a frame writer on top of a given writer
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, now: time.Now}
+}
+
+// WriteFrame writes data as one or more frames for a given stream, marking them with the current time
+// (or the time of the previous frame, if the clock has gone backwards)
+func (w *Writer) WriteFrame(stream Stream, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	capturedAt := w.now().UnixNano()
+	if capturedAt < w.last {
+		capturedAt = w.last
+	}
+	w.last = capturedAt
+	for len(data) > 0 {
+		size := len(data)
+		if size > MaxFrameSize {
+			size = MaxFrameSize
+		}
+
+		// Write the whole frame at once, so that readers never see frames from different streams interleaved
+		frame := make([]byte, frameOverhead+size)
+		frame[0] = byte(stream)
+		binary.BigEndian.PutUint64(frame[1:9], uint64(capturedAt))
+		binary.BigEndian.PutUint32(frame[9:headerSize], uint32(size))
+		copy(frame[headerSize:], data[:size])
+		binary.BigEndian.PutUint32(frame[headerSize+size:], uint32(size))
+
+		_, err := w.w.Write(frame)
+		if err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// StreamWriter returns an io.Writer writing all data as frames of a given stream
+func (w *Writer) StreamWriter(stream Stream) io.Writer {
+	return &streamWriter{writer: w, stream: stream}
+}
+
+type streamWriter struct {
+	writer *Writer
+	stream Stream
+}
+
+func (s *streamWriter) Write(data []byte) (int, error) {
+	err := s.writer.WriteFrame(s.stream, data)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+//-------------------------------------------------------------------------------------------------
+// Reader reads frames from an underlying reader
+type Reader struct {
+	r      io.Reader
+	header [headerSize]byte
+	offset int64 // Position in the log right after the last complete frame
+	skip   int   // Number of data bytes to drop from the next frame
+}
+
+// NewReader creates a frame reader on top of a given reader positioned at the start of the log
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// NewReaderAt creates a frame reader on top of a given reader positioned at a given offset in the log.
+// The offset must be at a frame boundary (0 or a value returned by Offset()).
+func NewReaderAt(r io.Reader, offset int64) *Reader {
+	return &Reader{r: r, offset: offset}
+}
+
+// NewReaderAtPosition creates a frame reader starting at a given position (see LastLines and LastBytes).
+// The reader must be positioned at the offset of the frame.
+func NewReaderAtPosition(r io.Reader, position Position) *Reader {
+	return &Reader{r: r, offset: position.Offset, skip: position.Skip}
+}
+
+// Offset returns the position in the log right after the last frame read,
+// which is where reading should be resumed from
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// ReadFrame returns the next frame from the log.
+// Returns io.EOF when there are no more complete frames available.
+func (r *Reader) ReadFrame() (Frame, error) {
+	_, err := io.ReadFull(r.r, r.header[:])
+	if err != nil {
+		return Frame{}, eofError(err)
+	}
+
+	stream, capturedAt, size, err := parseHeader(r.header[:])
+	if err != nil {
+		return Frame{}, err
+	}
+
+	data := make([]byte, size+trailerSize)
+	_, err = io.ReadFull(r.r, data)
+	if err != nil {
+		return Frame{}, eofError(err)
+	}
+	if binary.BigEndian.Uint32(data[size:]) != uint32(size) {
+		return Frame{}, ErrCorrupted
+	}
+
+	r.offset += frameOverhead + int64(size)
+	if r.skip > size {
+		r.skip = size
+	}
+	data = data[r.skip:size]
+	r.skip = 0
+	return Frame{Stream: stream, Time: capturedAt, Data: data}, nil
+}
+
+// Returns the stream, the capture time and the data size from a frame header
+func parseHeader(header []byte) (Stream, time.Time, int, error) {
+	stream := Stream(header[0])
+	capturedAt := time.Unix(0, int64(binary.BigEndian.Uint64(header[1:9])))
+	size := binary.BigEndian.Uint32(header[9:headerSize])
+	if (stream != Stdout && stream != Stderr) || size > MaxFrameSize {
+		return 0, time.Time{}, 0, ErrCorrupted
+	}
+	return stream, capturedAt, int(size), nil
+}
+
+// A partially written frame at the end of the log means we can't read any more frames
+func eofError(err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return io.EOF
+	}
+	return err
+}
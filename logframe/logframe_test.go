@@ -0,0 +1,114 @@
+package logframe
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLogFrame(t *testing.T) {
+	Convey("Frames", t, func() {
+		buffer := &bytes.Buffer{}
+		writer := NewWriter(buffer)
+
+		Convey("Should be read back with their streams", func() {
+			io.WriteString(writer.StreamWriter(Stdout), "hello")
+			io.WriteString(writer.StreamWriter(Stderr), "oops")
+
+			reader := NewReader(buffer)
+			frame, err := reader.ReadFrame()
+			So(err, ShouldBeNil)
+			So(frame.Stream, ShouldEqual, Stdout)
+			So(string(frame.Data), ShouldEqual, "hello")
+
+			frame, err = reader.ReadFrame()
+			So(err, ShouldBeNil)
+			So(frame.Stream, ShouldEqual, Stderr)
+			So(string(frame.Data), ShouldEqual, "oops")
+
+			_, err = reader.ReadFrame()
+			So(err, ShouldEqual, io.EOF)
+		})
+
+		Convey("Should record the capture time of each frame", func() {
+			capturedAt := time.Date(2021, 11, 20, 10, 30, 0, 123, time.UTC)
+			writer.now = func() time.Time { return capturedAt }
+			writer.WriteFrame(Stdout, []byte("hello"))
+
+			frame, err := NewReader(buffer).ReadFrame()
+			So(err, ShouldBeNil)
+			So(frame.Time.Equal(capturedAt), ShouldBeTrue)
+		})
+
+		Convey("Should never record a capture time earlier than the previous one", func() {
+			capturedAt := time.Date(2021, 11, 20, 10, 30, 0, 0, time.UTC)
+			writer.now = func() time.Time { return capturedAt }
+			writer.WriteFrame(Stdout, []byte("one"))
+			capturedAt = capturedAt.Add(-time.Hour)
+			writer.WriteFrame(Stdout, []byte("two"))
+
+			reader := NewReader(buffer)
+			first, _ := reader.ReadFrame()
+			second, err := reader.ReadFrame()
+			So(err, ShouldBeNil)
+			So(second.Time.Equal(first.Time), ShouldBeTrue)
+		})
+
+		Convey("Should track the offset to resume reading from", func() {
+			writer.WriteFrame(Stdout, []byte("one"))
+			writer.WriteFrame(Stderr, []byte("two"))
+			log := buffer.Bytes()
+
+			reader := NewReader(bytes.NewReader(log))
+			So(reader.Offset(), ShouldEqual, 0)
+			reader.ReadFrame()
+			offset := reader.Offset()
+			So(offset, ShouldEqual, frameOverhead+3)
+
+			resumed := NewReaderAt(bytes.NewReader(log[offset:]), offset)
+			frame, err := resumed.ReadFrame()
+			So(err, ShouldBeNil)
+			So(string(frame.Data), ShouldEqual, "two")
+			So(resumed.Offset(), ShouldEqual, len(log))
+		})
+
+		Convey("Should split large writes into multiple frames", func() {
+			data := bytes.Repeat([]byte("x"), MaxFrameSize+10)
+			So(writer.WriteFrame(Stdout, data), ShouldBeNil)
+
+			reader := NewReader(buffer)
+			frame, _ := reader.ReadFrame()
+			So(frame.Data, ShouldHaveLength, MaxFrameSize)
+			frame, _ = reader.ReadFrame()
+			So(frame.Data, ShouldHaveLength, 10)
+		})
+
+		Convey("Should not write anything for empty writes", func() {
+			So(writer.WriteFrame(Stdout, nil), ShouldBeNil)
+			So(buffer.Len(), ShouldEqual, 0)
+		})
+
+		Convey("Should treat a partially written frame as the end of the log", func() {
+			writer.WriteFrame(Stdout, []byte("complete"))
+			writer.WriteFrame(Stdout, []byte("partial"))
+			buffer.Truncate(buffer.Len() - 3)
+
+			reader := NewReader(buffer)
+			frame, err := reader.ReadFrame()
+			So(err, ShouldBeNil)
+			So(string(frame.Data), ShouldEqual, "complete")
+
+			_, err = reader.ReadFrame()
+			So(err, ShouldEqual, io.EOF)
+		})
+
+		Convey("Should detect corrupted frames", func() {
+			buffer.WriteString("this is not a framed log")
+			_, err := NewReader(buffer).ReadFrame()
+			So(err, ShouldEqual, ErrCorrupted)
+		})
+	})
+}
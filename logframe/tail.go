@@ -0,0 +1,217 @@
+package logframe
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Position points at a byte within the data of a frame
+type Position struct {
+	Offset int64 // Offset of the frame in the log
+	Skip   int   // Number of data bytes at the start of the frame to skip
+}
+
+// LastBytes scans the log backwards and returns the position of the last n bytes of data
+// written into a given stream (0 for all streams). Returns the start of the log if there is less data.
+func LastBytes(log io.ReaderAt, size int64, n int64, stream Stream) (Position, error) {
+	position := Position{Offset: size}
+	if n <= 0 {
+		return position, nil
+	}
+
+	remaining := n
+	err := scanBackwards(log, size, func(frame frameInfo) (bool, error) {
+		if !stream.Matches(frame.stream) || frame.size == 0 {
+			return false, nil
+		}
+
+		if int64(frame.size) >= remaining {
+			position = frame.position(frame.size - int(remaining))
+			return true, nil
+		}
+
+		remaining -= int64(frame.size)
+		position = frame.position(0)
+		return false, nil
+	})
+	return position, err
+}
+
+// LastLines scans the log backwards and returns the position of the last n lines of data
+// written into a given stream (0 for all streams). Same as with tail -n, a newline at the very
+// end of the output does not start a new line. Returns the start of the log if there are less lines.
+func LastLines(log io.ReaderAt, size int64, n int64, stream Stream) (Position, error) {
+	position := Position{Offset: size}
+	if n <= 0 {
+		return position, nil
+	}
+
+	scanner := NewLineScanner(n)
+	err := scanBackwards(log, size, func(frame frameInfo) (bool, error) {
+		if !stream.Matches(frame.stream) || frame.size == 0 {
+			return false, nil
+		}
+
+		data := make([]byte, frame.size)
+		_, err := log.ReadAt(data, frame.offset+headerSize)
+		if err != nil {
+			return false, err
+		}
+
+		skip, found := scanner.Scan(data)
+		if found {
+			position = frame.position(skip)
+			return true, nil
+		}
+
+		position = frame.position(0)
+		return false, nil
+	})
+	return position, err
+}
+
+// Since scans the log backwards and returns the position of the first frame captured at or after a given time.
+// Returns the end of the log if there are no such frames.
+func Since(log io.ReaderAt, size int64, since time.Time) (Position, error) {
+	position := Position{Offset: size}
+	err := scanBackwards(log, size, func(frame frameInfo) (bool, error) {
+		if frame.time.Before(since) {
+			return true, nil
+		}
+
+		position = frame.position(0)
+		return false, nil
+	})
+	return position, err
+}
+
+// IsFrameStart checks whether a given offset of the log is at a frame boundary: the frame before it must end
+// exactly there, and a complete header must be valid. The start and the end of the log are always boundaries.
+func IsFrameStart(log io.ReaderAt, size int64, offset int64) (bool, error) {
+	if offset < 0 || offset > size {
+		return false, nil
+	}
+
+	if offset > 0 {
+		_, err := frameBefore(log, offset)
+		if err == ErrCorrupted {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// The next frame may still be being written
+	if size-offset < headerSize {
+		return true, nil
+	}
+
+	var header [headerSize]byte
+	_, err := log.ReadAt(header[:], offset)
+	if err != nil {
+		return false, err
+	}
+	_, _, _, err = parseHeader(header[:])
+	return err == nil, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+// LineScanner looks for the beginning of the last n lines of some data, which is scanned backwards in chunks.
+// Same as with tail -n, a newline at the very end of the data does not start a new line.
+type LineScanner struct {
+	n        int64
+	newlines int64
+	atEnd    bool // Nothing has been scanned yet
+}
+
+// NewLineScanner creates a scanner looking for the last n lines (n must be positive)
+func NewLineScanner(n int64) *LineScanner {
+	return &LineScanner{n: n, atEnd: true}
+}
+
+// Scan takes the chunk of data right before the previously scanned one. Returns the position within the chunk
+// where the last n lines begin, or false if they begin somewhere before it.
+func (s *LineScanner) Scan(chunk []byte) (int, bool) {
+	for i := len(chunk) - 1; i >= 0; i-- {
+		if chunk[i] == '\n' && !s.atEnd {
+			s.newlines++
+			if s.newlines == s.n {
+				return i + 1, true
+			}
+		}
+		s.atEnd = false
+	}
+	return 0, false
+}
+
+//-------------------------------------------------------------------------------------------------
+// Location and metadata of a frame found while scanning the log
+type frameInfo struct {
+	offset int64
+	stream Stream
+	time   time.Time
+	size   int
+}
+
+// Returns the position of a given data byte in the frame,
+// pointing to the next frame instead of skipping all of the data
+func (f frameInfo) position(skip int) Position {
+	if skip >= f.size {
+		return Position{Offset: f.offset + frameOverhead + int64(f.size)}
+	}
+	return Position{Offset: f.offset, Skip: skip}
+}
+
+// Calls fn for each frame in the log, starting from the end, until it returns true or an error
+func scanBackwards(log io.ReaderAt, size int64, fn func(frame frameInfo) (bool, error)) error {
+	for end := size; end > 0; {
+		frame, err := frameBefore(log, end)
+		if err != nil {
+			return err
+		}
+
+		done, err := fn(frame)
+		if err != nil || done {
+			return err
+		}
+		end = frame.offset
+	}
+	return nil
+}
+
+// Returns the frame ending at a given offset, found through its trailer and checked against its header
+func frameBefore(log io.ReaderAt, end int64) (frameInfo, error) {
+	var header [headerSize]byte
+	var trailer [trailerSize]byte
+
+	if end < frameOverhead {
+		return frameInfo{}, ErrCorrupted
+	}
+
+	_, err := log.ReadAt(trailer[:], end-trailerSize)
+	if err != nil {
+		return frameInfo{}, err
+	}
+
+	dataSize := int64(binary.BigEndian.Uint32(trailer[:]))
+	offset := end - frameOverhead - dataSize
+	if dataSize > MaxFrameSize || offset < 0 {
+		return frameInfo{}, ErrCorrupted
+	}
+
+	_, err = log.ReadAt(header[:], offset)
+	if err != nil {
+		return frameInfo{}, err
+	}
+
+	stream, capturedAt, headerDataSize, err := parseHeader(header[:])
+	if err != nil {
+		return frameInfo{}, err
+	}
+	if int64(headerDataSize) != dataSize {
+		return frameInfo{}, ErrCorrupted
+	}
+	return frameInfo{offset: offset, stream: stream, time: capturedAt, size: headerDataSize}, nil
+}
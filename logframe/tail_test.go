@@ -0,0 +1,154 @@
+package logframe
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Reads all data of a given stream starting at a given position in the log
+func readFrom(log []byte, position Position, stream Stream) string {
+	reader := NewReaderAtPosition(bytes.NewReader(log[position.Offset:]), position)
+	output := ""
+	for {
+		frame, err := reader.ReadFrame()
+		if err != nil {
+			return output
+		}
+		if stream.Matches(frame.Stream) {
+			output += string(frame.Data)
+		}
+	}
+}
+
+func TestTail(t *testing.T) {
+	Convey("Scanning the log backwards", t, func() {
+		buffer := &bytes.Buffer{}
+		writer := NewWriter(buffer)
+		writer.WriteFrame(Stdout, []byte("one\ntwo\nth"))
+		writer.WriteFrame(Stderr, []byte("error\n"))
+		writer.WriteFrame(Stdout, []byte("ree\nfour\n"))
+		log := buffer.Bytes()
+		size := int64(len(log))
+		reader := bytes.NewReader(log)
+
+		Convey("LastLines()", func() {
+			Convey("Should find the last lines of all streams", func() {
+				position, err := LastLines(reader, size, 2, 0)
+				So(err, ShouldBeNil)
+				So(readFrom(log, position, 0), ShouldEqual, "ree\nfour\n")
+			})
+
+			Convey("Should only count the lines of a given stream", func() {
+				position, err := LastLines(reader, size, 2, Stdout)
+				So(err, ShouldBeNil)
+				So(readFrom(log, position, Stdout), ShouldEqual, "three\nfour\n")
+			})
+
+			Convey("Should not count a newline at the very end as a line", func() {
+				position, err := LastLines(reader, size, 1, 0)
+				So(err, ShouldBeNil)
+				So(readFrom(log, position, 0), ShouldEqual, "four\n")
+			})
+
+			Convey("Should return the start of the log when there are not enough lines", func() {
+				position, err := LastLines(reader, size, 100, 0)
+				So(err, ShouldBeNil)
+				So(position, ShouldResemble, Position{})
+			})
+
+			Convey("Should return the end of the log for zero lines", func() {
+				position, err := LastLines(reader, size, 0, 0)
+				So(err, ShouldBeNil)
+				So(position, ShouldResemble, Position{Offset: size})
+			})
+		})
+
+		Convey("LastBytes()", func() {
+			Convey("Should find the last bytes of all streams", func() {
+				position, err := LastBytes(reader, size, 12, 0)
+				So(err, ShouldBeNil)
+				So(readFrom(log, position, 0), ShouldEqual, "or\nree\nfour\n")
+			})
+
+			Convey("Should only count the bytes of a given stream", func() {
+				position, err := LastBytes(reader, size, 12, Stdout)
+				So(err, ShouldBeNil)
+				So(readFrom(log, position, Stdout), ShouldEqual, "\nthree\nfour\n")
+			})
+
+			Convey("Should point at the next frame instead of skipping a whole frame", func() {
+				position, err := LastBytes(reader, size, 9, 0)
+				So(err, ShouldBeNil)
+				So(position.Skip, ShouldEqual, 0)
+				So(readFrom(log, position, 0), ShouldEqual, "ree\nfour\n")
+			})
+		})
+
+		Convey("Since()", func() {
+			// Rewrite the log with frames captured a minute apart
+			start := time.Date(2021, 11, 20, 10, 0, 0, 0, time.UTC)
+			buffer.Reset()
+			writer := NewWriter(buffer)
+			writer.now = func() time.Time {
+				start = start.Add(time.Minute)
+				return start
+			}
+			writer.WriteFrame(Stdout, []byte("10:01\n"))
+			writer.WriteFrame(Stdout, []byte("10:02\n"))
+			writer.WriteFrame(Stdout, []byte("10:03\n"))
+			log := buffer.Bytes()
+			size := int64(len(log))
+			reader := bytes.NewReader(log)
+
+			Convey("Should find the first frame captured at or after a given time", func() {
+				position, err := Since(reader, size, time.Date(2021, 11, 20, 10, 2, 0, 0, time.UTC))
+				So(err, ShouldBeNil)
+				So(readFrom(log, position, 0), ShouldEqual, "10:02\n10:03\n")
+			})
+
+			Convey("Should return the end of the log if everything is older", func() {
+				position, err := Since(reader, size, time.Date(2021, 11, 20, 11, 0, 0, 0, time.UTC))
+				So(err, ShouldBeNil)
+				So(position, ShouldResemble, Position{Offset: size})
+			})
+		})
+
+		Convey("IsFrameStart()", func() {
+			Convey("Should accept frame boundaries", func() {
+				for _, offset := range []int64{0, 27, 50, size} {
+					ok, err := IsFrameStart(reader, size, offset)
+					So(err, ShouldBeNil)
+					So(ok, ShouldBeTrue)
+				}
+			})
+
+			Convey("Should reject offsets within frames", func() {
+				for _, offset := range []int64{1, 13, 26, 28, size - 1, size + 1} {
+					ok, err := IsFrameStart(reader, size, offset)
+					So(err, ShouldBeNil)
+					So(ok, ShouldBeFalse)
+				}
+			})
+
+			Convey("Should accept the start of a partially written frame", func() {
+				ok, err := IsFrameStart(bytes.NewReader(log[:55]), 55, 50)
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeTrue)
+			})
+		})
+
+		Convey("Should detect a corrupted log", func() {
+			_, err := LastLines(bytes.NewReader(log[:size-1]), size-1, 1, 0)
+			So(err, ShouldEqual, ErrCorrupted)
+		})
+
+		Convey("Should return read errors", func() {
+			_, err := LastBytes(reader, size+10, 1, 0)
+			So(err, ShouldEqual, io.EOF)
+		})
+	})
+}
@@ -0,0 +1,149 @@
+// Package certgen generates throwaway certificate authorities and certificates
+// for tests and local development environments.
+package certgen
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"time"
+)
+
+// How long generated certificates stay valid
+const validity = 365 * 24 * time.Hour
+
+// Host names and addresses included into server certificates
+var serverDNSNames = []string{"localhost"}
+var serverIPs = []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}
+
+type CA struct {
+	Cert    *x509.Certificate
+	CertPEM []byte
+	key     crypto.Signer
+}
+
+// NewCA generates a new self-signed certificate authority
+func NewCA(commonName string) (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a CA key: %w", err)
+	}
+
+	template, err := newTemplate(commonName)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a CA certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the CA certificate: %w", err)
+	}
+
+	return &CA{
+		Cert:    cert,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     key,
+	}, nil
+}
+
+// Issue generates a PEM-encoded certificate and key signed by the CA.
+// Server certificates are valid for localhost, client certificates carry a given common name.
+func (ca *CA) Issue(commonName string, server bool) (certPEM []byte, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate a key: %w", err)
+	}
+
+	template, err := newTemplate(commonName)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	if server {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.DNSNames = serverDNSNames
+		template.IPAddresses = serverIPs
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create a certificate for '%s': %w", commonName, err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode a key for '%s': %w", commonName, err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// WriteCA writes the CA certificate into <dir>/ca.crt and returns the file name
+func (ca *CA) WriteCA(dir string) (string, error) {
+	fileName := path.Join(dir, "ca.crt")
+	err := os.WriteFile(fileName, ca.CertPEM, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write '%s': %w", fileName, err)
+	}
+	return fileName, nil
+}
+
+// WriteCert issues a certificate and writes it into <dir>/<name>.crt and <dir>/<name>.key
+func (ca *CA) WriteCert(dir string, commonName string, server bool) (certFile string, keyFile string, err error) {
+	certPEM, keyPEM, err := ca.Issue(commonName, server)
+	if err != nil {
+		return "", "", err
+	}
+
+	certFile = path.Join(dir, commonName+".crt")
+	keyFile = path.Join(dir, commonName+".key")
+
+	err = os.WriteFile(certFile, certPEM, 0644)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write '%s': %w", certFile, err)
+	}
+
+	err = os.WriteFile(keyFile, keyPEM, 0600)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write '%s': %w", keyFile, err)
+	}
+	return certFile, keyFile, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+// Returns a certificate template with a random serial number and a given common name
+func newTemplate(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a serial number: %w", err)
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
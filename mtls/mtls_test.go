@@ -0,0 +1,128 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"os"
+	"path"
+	"testing"
+
+	"teleport-exec/mtls/certgen"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Starts a TLS listener accepting a single connection and returns its address and the handshake result channel
+func startTestListener(config *tls.Config) (string, chan error) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	So(err, ShouldBeNil)
+
+	result := make(chan error, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			result <- err
+			return
+		}
+		defer conn.Close()
+		result <- conn.(*tls.Conn).Handshake()
+	}()
+
+	return listener.Addr().String(), result
+}
+
+// Connects to a given address and performs a TLS handshake
+func handshake(addr string, config *tls.Config) error {
+	conn, err := tls.Dial("tcp", addr, config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// In TLS 1.3 client certificate errors are only reported by the server after the handshake
+	_, err = conn.Read(make([]byte, 1))
+	return err
+}
+
+func TestMTLS(t *testing.T) {
+	Convey("mTLS configs", t, func() {
+		dir, _ := os.MkdirTemp("", "mtls_test")
+
+		ca, err := certgen.NewCA("test-ca")
+		So(err, ShouldBeNil)
+		caFile, _ := ca.WriteCA(dir)
+		serverCert, serverKey, err := ca.WriteCert(dir, "server", true)
+		So(err, ShouldBeNil)
+		clientCert, clientKey, err := ca.WriteCert(dir, "alice", false)
+		So(err, ShouldBeNil)
+
+		serverConfig, err := ServerConfig(caFile, serverCert, serverKey)
+		So(err, ShouldBeNil)
+		clientConfig, err := ClientConfig(caFile, clientCert, clientKey)
+		So(err, ShouldBeNil)
+		clientConfig.ServerName = "localhost"
+
+		Convey("Should only allow TLS 1.3 and require client certificates", func() {
+			So(serverConfig.MinVersion, ShouldEqual, tls.VersionTLS13)
+			So(serverConfig.ClientAuth, ShouldEqual, tls.RequireAndVerifyClientCert)
+			So(clientConfig.MinVersion, ShouldEqual, tls.VersionTLS13)
+		})
+
+		Convey("Should establish a connection with a valid client certificate", func() {
+			addr, result := startTestListener(serverConfig)
+			conn, err := tls.Dial("tcp", addr, clientConfig)
+			So(err, ShouldBeNil)
+			defer conn.Close()
+
+			So(<-result, ShouldBeNil)
+			So(conn.ConnectionState().Version, ShouldEqual, tls.VersionTLS13)
+		})
+
+		Convey("Should reject clients without a certificate", func() {
+			addr, result := startTestListener(serverConfig)
+			noCertConfig := clientConfig.Clone()
+			noCertConfig.Certificates = nil
+
+			So(handshake(addr, noCertConfig), ShouldNotBeNil)
+			So(<-result, ShouldNotBeNil)
+		})
+
+		Convey("Should reject client certificates issued by a different CA", func() {
+			otherCA, _ := certgen.NewCA("other-ca")
+			otherDir := path.Join(dir, "other")
+			os.Mkdir(otherDir, 0700)
+			otherCert, otherKey, _ := otherCA.WriteCert(otherDir, "mallory", false)
+
+			otherConfig := clientConfig.Clone()
+			cert, err := tls.LoadX509KeyPair(otherCert, otherKey)
+			So(err, ShouldBeNil)
+			otherConfig.Certificates = []tls.Certificate{cert}
+
+			addr, result := startTestListener(serverConfig)
+			So(handshake(addr, otherConfig), ShouldNotBeNil)
+			So(<-result, ShouldNotBeNil)
+		})
+
+		Convey("Should reject clients limited to older TLS versions", func() {
+			oldConfig := clientConfig.Clone()
+			oldConfig.MinVersion = tls.VersionTLS12
+			oldConfig.MaxVersion = tls.VersionTLS12
+
+			addr, result := startTestListener(serverConfig)
+			So(handshake(addr, oldConfig), ShouldNotBeNil)
+			So(<-result, ShouldNotBeNil)
+		})
+
+		Convey("Should fail on missing files", func() {
+			_, err := ServerConfig(path.Join(dir, "missing.crt"), serverCert, serverKey)
+			So(err, ShouldNotBeNil)
+
+			_, err = ClientConfig(caFile, path.Join(dir, "missing.crt"), clientKey)
+			So(err, ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			os.RemoveAll(dir)
+		})
+	})
+}
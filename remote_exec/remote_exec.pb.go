@@ -9,6 +9,8 @@ package remote_exec
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -21,18 +23,177 @@ const (
 )
 
 //-----------------------------------------------------------------------------
+// Output stream of a command, ALL is only used to subscribe to both streams
+type OutputStream int32
+
+const (
+	OutputStream_ALL    OutputStream = 0
+	OutputStream_STDOUT OutputStream = 1
+	OutputStream_STDERR OutputStream = 2
+)
+
+// Enum value maps for OutputStream.
+var (
+	OutputStream_name = map[int32]string{
+		0: "ALL",
+		1: "STDOUT",
+		2: "STDERR",
+	}
+	OutputStream_value = map[string]int32{
+		"ALL":    0,
+		"STDOUT": 1,
+		"STDERR": 2,
+	}
+)
+
+func (x OutputStream) Enum() *OutputStream {
+	p := new(OutputStream)
+	*p = x
+	return p
+}
+
+func (x OutputStream) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OutputStream) Descriptor() protoreflect.EnumDescriptor {
+	return file_remote_exec_remote_exec_proto_enumTypes[0].Descriptor()
+}
+
+func (OutputStream) Type() protoreflect.EnumType {
+	return &file_remote_exec_remote_exec_proto_enumTypes[0]
+}
+
+func (x OutputStream) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OutputStream.Descriptor instead.
+func (OutputStream) EnumDescriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{0}
+}
+
+//-----------------------------------------------------------------------------
+// Resource limits for a command, unset fields fall back to the server defaults
+type ResourceLimits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MemoryBytes   *int64 `protobuf:"varint,1,opt,name=memory_bytes,json=memoryBytes,proto3,oneof" json:"memory_bytes,omitempty"`
+	CpuQuotaUsec  *int64 `protobuf:"varint,2,opt,name=cpu_quota_usec,json=cpuQuotaUsec,proto3,oneof" json:"cpu_quota_usec,omitempty"`
+	CpuPeriodUsec *int64 `protobuf:"varint,3,opt,name=cpu_period_usec,json=cpuPeriodUsec,proto3,oneof" json:"cpu_period_usec,omitempty"`
+	IoReadBps     *int64 `protobuf:"varint,4,opt,name=io_read_bps,json=ioReadBps,proto3,oneof" json:"io_read_bps,omitempty"`
+	IoWriteBps    *int64 `protobuf:"varint,5,opt,name=io_write_bps,json=ioWriteBps,proto3,oneof" json:"io_write_bps,omitempty"`
+	IoReadIops    *int64 `protobuf:"varint,6,opt,name=io_read_iops,json=ioReadIops,proto3,oneof" json:"io_read_iops,omitempty"`
+	IoWriteIops   *int64 `protobuf:"varint,7,opt,name=io_write_iops,json=ioWriteIops,proto3,oneof" json:"io_write_iops,omitempty"`
+	PidsMax       *int64 `protobuf:"varint,8,opt,name=pids_max,json=pidsMax,proto3,oneof" json:"pids_max,omitempty"`
+}
+
+func (x *ResourceLimits) Reset() {
+	*x = ResourceLimits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResourceLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceLimits) ProtoMessage() {}
+
+func (x *ResourceLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceLimits.ProtoReflect.Descriptor instead.
+func (*ResourceLimits) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ResourceLimits) GetMemoryBytes() int64 {
+	if x != nil && x.MemoryBytes != nil {
+		return *x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetCpuQuotaUsec() int64 {
+	if x != nil && x.CpuQuotaUsec != nil {
+		return *x.CpuQuotaUsec
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetCpuPeriodUsec() int64 {
+	if x != nil && x.CpuPeriodUsec != nil {
+		return *x.CpuPeriodUsec
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetIoReadBps() int64 {
+	if x != nil && x.IoReadBps != nil {
+		return *x.IoReadBps
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetIoWriteBps() int64 {
+	if x != nil && x.IoWriteBps != nil {
+		return *x.IoWriteBps
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetIoReadIops() int64 {
+	if x != nil && x.IoReadIops != nil {
+		return *x.IoReadIops
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetIoWriteIops() int64 {
+	if x != nil && x.IoWriteIops != nil {
+		return *x.IoWriteIops
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetPidsMax() int64 {
+	if x != nil && x.PidsMax != nil {
+		return *x.PidsMax
+	}
+	return 0
+}
+
 type StartCommandRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Command []string `protobuf:"bytes,1,rep,name=command,proto3" json:"command,omitempty"`
+	Command    []string        `protobuf:"bytes,1,rep,name=command,proto3" json:"command,omitempty"`
+	Limits     *ResourceLimits `protobuf:"bytes,2,opt,name=limits,proto3" json:"limits,omitempty"`
+	WorkingDir string          `protobuf:"bytes,3,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"` // Working directory for the command (server working directory if empty)
+	Env        []string        `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty"`                                 // Extra environment variables ("KEY=VALUE"), added to the server environment
+	Stdin      []byte          `protobuf:"bytes,5,opt,name=stdin,proto3" json:"stdin,omitempty"`                             // Data fed into the command stdin (limited by the maximum GRPC message size)
 }
 
 func (x *StartCommandRequest) Reset() {
 	*x = StartCommandRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[0]
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -45,7 +206,7 @@ func (x *StartCommandRequest) String() string {
 func (*StartCommandRequest) ProtoMessage() {}
 
 func (x *StartCommandRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[0]
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -58,7 +219,7 @@ func (x *StartCommandRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartCommandRequest.ProtoReflect.Descriptor instead.
 func (*StartCommandRequest) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{0}
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *StartCommandRequest) GetCommand() []string {
@@ -68,7 +229,107 @@ func (x *StartCommandRequest) GetCommand() []string {
 	return nil
 }
 
+func (x *StartCommandRequest) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *StartCommandRequest) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *StartCommandRequest) GetEnv() []string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *StartCommandRequest) GetStdin() []byte {
+	if x != nil {
+		return x.Stdin
+	}
+	return nil
+}
+
 //-----------------------------------------------------------------------------
+// Resources used by a command, read from its cgroup stats
+type ResourceUsage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PeakMemoryBytes int64 `protobuf:"varint,1,opt,name=peak_memory_bytes,json=peakMemoryBytes,proto3" json:"peak_memory_bytes,omitempty"` // Zero on kernels not tracking the peak memory usage (before Linux 5.19)
+	CpuTimeUsec     int64 `protobuf:"varint,2,opt,name=cpu_time_usec,json=cpuTimeUsec,proto3" json:"cpu_time_usec,omitempty"`
+	IoReadBytes     int64 `protobuf:"varint,3,opt,name=io_read_bytes,json=ioReadBytes,proto3" json:"io_read_bytes,omitempty"`
+	IoWriteBytes    int64 `protobuf:"varint,4,opt,name=io_write_bytes,json=ioWriteBytes,proto3" json:"io_write_bytes,omitempty"`
+}
+
+func (x *ResourceUsage) Reset() {
+	*x = ResourceUsage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResourceUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceUsage) ProtoMessage() {}
+
+func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
+func (*ResourceUsage) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResourceUsage) GetPeakMemoryBytes() int64 {
+	if x != nil {
+		return x.PeakMemoryBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetCpuTimeUsec() int64 {
+	if x != nil {
+		return x.CpuTimeUsec
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetIoReadBytes() int64 {
+	if x != nil {
+		return x.IoReadBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetIoWriteBytes() int64 {
+	if x != nil {
+		return x.IoWriteBytes
+	}
+	return 0
+}
+
 type CommandStatusRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -80,7 +341,7 @@ type CommandStatusRequest struct {
 func (x *CommandStatusRequest) Reset() {
 	*x = CommandStatusRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[1]
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -93,7 +354,7 @@ func (x *CommandStatusRequest) String() string {
 func (*CommandStatusRequest) ProtoMessage() {}
 
 func (x *CommandStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[1]
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -106,7 +367,7 @@ func (x *CommandStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommandStatusRequest.ProtoReflect.Descriptor instead.
 func (*CommandStatusRequest) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{1}
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CommandStatusRequest) GetCommandId() string {
@@ -121,17 +382,26 @@ type CommandStatusResponse struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	CommandId  string `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
-	Command    string `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
-	Running    bool   `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
-	ResultCode *int32 `protobuf:"varint,4,opt,name=result_code,json=resultCode,proto3,oneof" json:"result_code,omitempty"`
-	Exited     *bool  `protobuf:"varint,5,opt,name=exited,proto3,oneof" json:"exited,omitempty"`
+	CommandId  string                 `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Command    string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Running    bool                   `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
+	ResultCode *int32                 `protobuf:"varint,4,opt,name=result_code,json=resultCode,proto3,oneof" json:"result_code,omitempty"`
+	Exited     *bool                  `protobuf:"varint,5,opt,name=exited,proto3,oneof" json:"exited,omitempty"`
+	Lost       bool                   `protobuf:"varint,6,opt,name=lost,proto3" json:"lost,omitempty"`    // The server has restarted while the command was running, so its result is unknown
+	Signal     string                 `protobuf:"bytes,7,opt,name=signal,proto3" json:"signal,omitempty"` // Signal which has ended the process (e.g. SIGTERM), empty if it has exited
+	StartTime  *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`         // Unset while running or if the command is lost
+	Duration   *durationpb.Duration   `protobuf:"bytes,10,opt,name=duration,proto3" json:"duration,omitempty"`                     // How long the command has been running (unset if the command is lost)
+	OomKilled  bool                   `protobuf:"varint,11,opt,name=oom_killed,json=oomKilled,proto3" json:"oom_killed,omitempty"` // A process of the command has been killed for running out of memory
+	Owner      string                 `protobuf:"bytes,12,opt,name=owner,proto3" json:"owner,omitempty"`                           // Common name of the client who started the command
+	Pid        int64                  `protobuf:"varint,13,opt,name=pid,proto3" json:"pid,omitempty"`                              // Host PID of the container init running the command, not of the command itself
+	Usage      *ResourceUsage         `protobuf:"bytes,14,opt,name=usage,proto3" json:"usage,omitempty"`                           // Set once finished, if resource limits are enabled on the server
 }
 
 func (x *CommandStatusResponse) Reset() {
 	*x = CommandStatusResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[2]
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -144,7 +414,7 @@ func (x *CommandStatusResponse) String() string {
 func (*CommandStatusResponse) ProtoMessage() {}
 
 func (x *CommandStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[2]
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -157,118 +427,503 @@ func (x *CommandStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommandStatusResponse.ProtoReflect.Descriptor instead.
 func (*CommandStatusResponse) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{2}
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CommandStatusResponse) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+func (x *CommandStatusResponse) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *CommandStatusResponse) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *CommandStatusResponse) GetResultCode() int32 {
+	if x != nil && x.ResultCode != nil {
+		return *x.ResultCode
+	}
+	return 0
+}
+
+func (x *CommandStatusResponse) GetExited() bool {
+	if x != nil && x.Exited != nil {
+		return *x.Exited
+	}
+	return false
+}
+
+func (x *CommandStatusResponse) GetLost() bool {
+	if x != nil {
+		return x.Lost
+	}
+	return false
+}
+
+func (x *CommandStatusResponse) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *CommandStatusResponse) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *CommandStatusResponse) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *CommandStatusResponse) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *CommandStatusResponse) GetOomKilled() bool {
+	if x != nil {
+		return x.OomKilled
+	}
+	return false
+}
+
+func (x *CommandStatusResponse) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *CommandStatusResponse) GetPid() int64 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *CommandStatusResponse) GetUsage() *ResourceUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+type StopCommandRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CommandId   string               `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Signal      string               `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`                              // Signal sent to the command first (TERM, INT, HUP, QUIT, USR1 or USR2), SIGKILL if empty
+	GracePeriod *durationpb.Duration `protobuf:"bytes,3,opt,name=grace_period,json=gracePeriod,proto3" json:"grace_period,omitempty"` // How long to wait for the command to finish before sending SIGKILL
+}
+
+func (x *StopCommandRequest) Reset() {
+	*x = StopCommandRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopCommandRequest) ProtoMessage() {}
+
+func (x *StopCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopCommandRequest.ProtoReflect.Descriptor instead.
+func (*StopCommandRequest) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StopCommandRequest) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+func (x *StopCommandRequest) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *StopCommandRequest) GetGracePeriod() *durationpb.Duration {
+	if x != nil {
+		return x.GracePeriod
+	}
+	return nil
+}
+
+type StopCommandResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CommandId string `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Success   bool   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+}
+
+func (x *StopCommandResponse) Reset() {
+	*x = StopCommandResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopCommandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopCommandResponse) ProtoMessage() {}
+
+func (x *StopCommandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopCommandResponse.ProtoReflect.Descriptor instead.
+func (*StopCommandResponse) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StopCommandResponse) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+func (x *StopCommandResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+//-----------------------------------------------------------------------------
+// Only finished commands can be deleted, along with their output
+type DeleteCommandRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CommandId string `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+}
+
+func (x *DeleteCommandRequest) Reset() {
+	*x = DeleteCommandRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCommandRequest) ProtoMessage() {}
+
+func (x *DeleteCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCommandRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCommandRequest) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteCommandRequest) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+type DeleteCommandResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CommandId string `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Success   bool   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+}
+
+func (x *DeleteCommandResponse) Reset() {
+	*x = DeleteCommandResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteCommandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCommandResponse) ProtoMessage() {}
+
+func (x *DeleteCommandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCommandResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCommandResponse) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteCommandResponse) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+func (x *DeleteCommandResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type CommandOutputRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CommandId       string                 `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Tail            bool                   `protobuf:"varint,2,opt,name=tail,proto3" json:"tail,omitempty"`                                                 // Keep streaming until the command has finished
+	Stream          OutputStream           `protobuf:"varint,3,opt,name=stream,proto3,enum=remote_exec.OutputStream" json:"stream,omitempty"`               // Stream(s) to return the output for
+	StartOffset     int64                  `protobuf:"varint,4,opt,name=start_offset,json=startOffset,proto3" json:"start_offset,omitempty"`                // Log offset to start from: 0 or the offset of the last block received before
+	LastLines       int64                  `protobuf:"varint,5,opt,name=last_lines,json=lastLines,proto3" json:"last_lines,omitempty"`                      // Start from the last N lines of the output (like tail -n)
+	LastBytes       int64                  `protobuf:"varint,6,opt,name=last_bytes,json=lastBytes,proto3" json:"last_bytes,omitempty"`                      // Start from the last N bytes of the output (like tail -c)
+	Lines           bool                   `protobuf:"varint,7,opt,name=lines,proto3" json:"lines,omitempty"`                                               // Send whole lines (partial lines are sent after a second without output)
+	Since           *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=since,proto3" json:"since,omitempty"`                                                // Only send the output captured at or after this time
+	Until           *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=until,proto3" json:"until,omitempty"`                                                // Only send the output captured at or before this time
+	StartSkipStdout int64                  `protobuf:"varint,10,opt,name=start_skip_stdout,json=startSkipStdout,proto3" json:"start_skip_stdout,omitempty"` // Stdout data at start_offset to skip: skip_stdout of the last block received before
+	StartSkipStderr int64                  `protobuf:"varint,11,opt,name=start_skip_stderr,json=startSkipStderr,proto3" json:"start_skip_stderr,omitempty"` // Stderr data at start_offset to skip: skip_stderr of the last block received before
+	MaxLineLength   int32                  `protobuf:"varint,12,opt,name=max_line_length,json=maxLineLength,proto3" json:"max_line_length,omitempty"`       // Longer lines are split in the line mode (64 KiB if unset)
+	LineIdleTimeout *durationpb.Duration   `protobuf:"bytes,13,opt,name=line_idle_timeout,json=lineIdleTimeout,proto3" json:"line_idle_timeout,omitempty"`  // A partial line is sent after no output for this long (a second if unset)
+}
+
+func (x *CommandOutputRequest) Reset() {
+	*x = CommandOutputRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandOutputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandOutputRequest) ProtoMessage() {}
+
+func (x *CommandOutputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandOutputRequest.ProtoReflect.Descriptor instead.
+func (*CommandOutputRequest) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CommandOutputRequest) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+func (x *CommandOutputRequest) GetTail() bool {
+	if x != nil {
+		return x.Tail
+	}
+	return false
 }
 
-func (x *CommandStatusResponse) GetCommandId() string {
+func (x *CommandOutputRequest) GetStream() OutputStream {
 	if x != nil {
-		return x.CommandId
+		return x.Stream
 	}
-	return ""
+	return OutputStream_ALL
 }
 
-func (x *CommandStatusResponse) GetCommand() string {
+func (x *CommandOutputRequest) GetStartOffset() int64 {
 	if x != nil {
-		return x.Command
+		return x.StartOffset
 	}
-	return ""
+	return 0
 }
 
-func (x *CommandStatusResponse) GetRunning() bool {
+func (x *CommandOutputRequest) GetLastLines() int64 {
 	if x != nil {
-		return x.Running
+		return x.LastLines
 	}
-	return false
+	return 0
 }
 
-func (x *CommandStatusResponse) GetResultCode() int32 {
-	if x != nil && x.ResultCode != nil {
-		return *x.ResultCode
+func (x *CommandOutputRequest) GetLastBytes() int64 {
+	if x != nil {
+		return x.LastBytes
 	}
 	return 0
 }
 
-func (x *CommandStatusResponse) GetExited() bool {
-	if x != nil && x.Exited != nil {
-		return *x.Exited
+func (x *CommandOutputRequest) GetLines() bool {
+	if x != nil {
+		return x.Lines
 	}
 	return false
 }
 
-//-----------------------------------------------------------------------------
-type StopCommandRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	CommandId string `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+func (x *CommandOutputRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
 }
 
-func (x *StopCommandRequest) Reset() {
-	*x = StopCommandRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *CommandOutputRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
 	}
+	return nil
 }
 
-func (x *StopCommandRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *CommandOutputRequest) GetStartSkipStdout() int64 {
+	if x != nil {
+		return x.StartSkipStdout
+	}
+	return 0
 }
 
-func (*StopCommandRequest) ProtoMessage() {}
-
-func (x *StopCommandRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *CommandOutputRequest) GetStartSkipStderr() int64 {
+	if x != nil {
+		return x.StartSkipStderr
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use StopCommandRequest.ProtoReflect.Descriptor instead.
-func (*StopCommandRequest) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{3}
+func (x *CommandOutputRequest) GetMaxLineLength() int32 {
+	if x != nil {
+		return x.MaxLineLength
+	}
+	return 0
 }
 
-func (x *StopCommandRequest) GetCommandId() string {
+func (x *CommandOutputRequest) GetLineIdleTimeout() *durationpb.Duration {
 	if x != nil {
-		return x.CommandId
+		return x.LineIdleTimeout
 	}
-	return ""
+	return nil
 }
 
-type StopCommandResponse struct {
+type CommandOutputBlock struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	CommandId string `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
-	Success   bool   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Output     []byte                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	Stream     OutputStream           `protobuf:"varint,2,opt,name=stream,proto3,enum=remote_exec.OutputStream" json:"stream,omitempty"` // Stream the output came from (STDOUT or STDERR)
+	Offset     int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`                               // Log offset right after this block, used as start_offset to resume streaming
+	Time       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`                                    // When the output has been captured
+	SkipStdout int64                  `protobuf:"varint,5,opt,name=skip_stdout,json=skipStdout,proto3" json:"skip_stdout,omitempty"`     // Stdout data at offset already sent (only in the line mode), used as start_skip_stdout
+	SkipStderr int64                  `protobuf:"varint,6,opt,name=skip_stderr,json=skipStderr,proto3" json:"skip_stderr,omitempty"`     // Stderr data at offset already sent (only in the line mode), used as start_skip_stderr
 }
 
-func (x *StopCommandResponse) Reset() {
-	*x = StopCommandResponse{}
+func (x *CommandOutputBlock) Reset() {
+	*x = CommandOutputBlock{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[4]
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StopCommandResponse) String() string {
+func (x *CommandOutputBlock) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopCommandResponse) ProtoMessage() {}
+func (*CommandOutputBlock) ProtoMessage() {}
 
-func (x *StopCommandResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[4]
+func (x *CommandOutputBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -279,51 +934,81 @@ func (x *StopCommandResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopCommandResponse.ProtoReflect.Descriptor instead.
-func (*StopCommandResponse) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use CommandOutputBlock.ProtoReflect.Descriptor instead.
+func (*CommandOutputBlock) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *StopCommandResponse) GetCommandId() string {
+func (x *CommandOutputBlock) GetOutput() []byte {
 	if x != nil {
-		return x.CommandId
+		return x.Output
 	}
-	return ""
+	return nil
 }
 
-func (x *StopCommandResponse) GetSuccess() bool {
+func (x *CommandOutputBlock) GetStream() OutputStream {
 	if x != nil {
-		return x.Success
+		return x.Stream
 	}
-	return false
+	return OutputStream_ALL
+}
+
+func (x *CommandOutputBlock) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *CommandOutputBlock) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *CommandOutputBlock) GetSkipStdout() int64 {
+	if x != nil {
+		return x.SkipStdout
+	}
+	return 0
+}
+
+func (x *CommandOutputBlock) GetSkipStderr() int64 {
+	if x != nil {
+		return x.SkipStderr
+	}
+	return 0
 }
 
 //-----------------------------------------------------------------------------
-type CommandOutputRequest struct {
+// Only available for running commands when resource limits are enabled on the server
+type WatchCommandStatsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	CommandId string `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	CommandId string               `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Interval  *durationpb.Duration `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"` // How often to sample the stats (every second if unset)
 }
 
-func (x *CommandOutputRequest) Reset() {
-	*x = CommandOutputRequest{}
+func (x *WatchCommandStatsRequest) Reset() {
+	*x = WatchCommandStatsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[5]
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *CommandOutputRequest) String() string {
+func (x *WatchCommandStatsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CommandOutputRequest) ProtoMessage() {}
+func (*WatchCommandStatsRequest) ProtoMessage() {}
 
-func (x *CommandOutputRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[5]
+func (x *WatchCommandStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -334,43 +1019,56 @@ func (x *CommandOutputRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CommandOutputRequest.ProtoReflect.Descriptor instead.
-func (*CommandOutputRequest) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use WatchCommandStatsRequest.ProtoReflect.Descriptor instead.
+func (*WatchCommandStatsRequest) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *CommandOutputRequest) GetCommandId() string {
+func (x *WatchCommandStatsRequest) GetCommandId() string {
 	if x != nil {
 		return x.CommandId
 	}
 	return ""
 }
 
-type CommandOutputBlock struct {
+func (x *WatchCommandStatsRequest) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+// Resources used by a running command at a given time, read from its cgroup stats
+type CommandStats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Output []byte `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	Time         *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	MemoryBytes  int64                  `protobuf:"varint,2,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`      // Current memory usage
+	CpuTimeUsec  int64                  `protobuf:"varint,3,opt,name=cpu_time_usec,json=cpuTimeUsec,proto3" json:"cpu_time_usec,omitempty"`    // CPU time used since the command has started
+	IoReadBytes  int64                  `protobuf:"varint,4,opt,name=io_read_bytes,json=ioReadBytes,proto3" json:"io_read_bytes,omitempty"`    // Bytes read from disk since the command has started
+	IoWriteBytes int64                  `protobuf:"varint,5,opt,name=io_write_bytes,json=ioWriteBytes,proto3" json:"io_write_bytes,omitempty"` // Bytes written to disk since the command has started
+	Pids         int64                  `protobuf:"varint,6,opt,name=pids,proto3" json:"pids,omitempty"`                                       // Current number of processes of the command (the container init is not included)
 }
 
-func (x *CommandOutputBlock) Reset() {
-	*x = CommandOutputBlock{}
+func (x *CommandStats) Reset() {
+	*x = CommandStats{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[6]
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *CommandOutputBlock) String() string {
+func (x *CommandStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CommandOutputBlock) ProtoMessage() {}
+func (*CommandStats) ProtoMessage() {}
 
-func (x *CommandOutputBlock) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[6]
+func (x *CommandStats) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -381,18 +1079,53 @@ func (x *CommandOutputBlock) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CommandOutputBlock.ProtoReflect.Descriptor instead.
-func (*CommandOutputBlock) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use CommandStats.ProtoReflect.Descriptor instead.
+func (*CommandStats) Descriptor() ([]byte, []int) {
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *CommandOutputBlock) GetOutput() []byte {
+func (x *CommandStats) GetTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Output
+		return x.Time
 	}
 	return nil
 }
 
+func (x *CommandStats) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *CommandStats) GetCpuTimeUsec() int64 {
+	if x != nil {
+		return x.CpuTimeUsec
+	}
+	return 0
+}
+
+func (x *CommandStats) GetIoReadBytes() int64 {
+	if x != nil {
+		return x.IoReadBytes
+	}
+	return 0
+}
+
+func (x *CommandStats) GetIoWriteBytes() int64 {
+	if x != nil {
+		return x.IoWriteBytes
+	}
+	return 0
+}
+
+func (x *CommandStats) GetPids() int64 {
+	if x != nil {
+		return x.Pids
+	}
+	return 0
+}
+
 //-----------------------------------------------------------------------------
 type StatusRequest struct {
 	state         protoimpl.MessageState
@@ -403,7 +1136,7 @@ type StatusRequest struct {
 func (x *StatusRequest) Reset() {
 	*x = StatusRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[7]
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -416,7 +1149,7 @@ func (x *StatusRequest) String() string {
 func (*StatusRequest) ProtoMessage() {}
 
 func (x *StatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[7]
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,7 +1162,7 @@ func (x *StatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
 func (*StatusRequest) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{7}
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{13}
 }
 
 type StatusResponse struct {
@@ -445,7 +1178,7 @@ type StatusResponse struct {
 func (x *StatusResponse) Reset() {
 	*x = StatusResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_exec_remote_exec_proto_msgTypes[8]
+		mi := &file_remote_exec_remote_exec_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -458,7 +1191,7 @@ func (x *StatusResponse) String() string {
 func (*StatusResponse) ProtoMessage() {}
 
 func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_exec_remote_exec_proto_msgTypes[8]
+	mi := &file_remote_exec_remote_exec_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -471,7 +1204,7 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{8}
+	return file_remote_exec_remote_exec_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *StatusResponse) GetVersion() string {
@@ -500,79 +1233,244 @@ var File_remote_exec_remote_exec_proto protoreflect.FileDescriptor
 var file_remote_exec_remote_exec_proto_rawDesc = []byte{
 	0x0a, 0x1d, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2f, 0x72, 0x65,
 	0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x0b, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x22, 0x2f, 0x0a, 0x13,
-	0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x35, 0x0a,
-	0x14, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x49, 0x64, 0x22, 0xc8, 0x01, 0x0a, 0x15, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d,
-	0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x18, 0x0a,
-	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69,
-	0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e,
-	0x67, 0x12, 0x24, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x43, 0x6f, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x65, 0x78, 0x69, 0x74, 0x65,
-	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x48, 0x01, 0x52, 0x06, 0x65, 0x78, 0x69, 0x74, 0x65,
-	0x64, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f,
-	0x63, 0x6f, 0x64, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x65, 0x78, 0x69, 0x74, 0x65, 0x64, 0x22,
-	0x33, 0x0a, 0x12, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65,
+	0x0b, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x1a, 0x1e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd5, 0x03,
+	0x0a, 0x0e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73,
+	0x12, 0x26, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0e, 0x63, 0x70, 0x75, 0x5f,
+	0x71, 0x75, 0x6f, 0x74, 0x61, 0x5f, 0x75, 0x73, 0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x48, 0x01, 0x52, 0x0c, 0x63, 0x70, 0x75, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x55, 0x73, 0x65, 0x63,
+	0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0f, 0x63, 0x70, 0x75, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x5f, 0x75, 0x73, 0x65, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x48, 0x02, 0x52, 0x0d,
+	0x63, 0x70, 0x75, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x55, 0x73, 0x65, 0x63, 0x88, 0x01, 0x01,
+	0x12, 0x23, 0x0a, 0x0b, 0x69, 0x6f, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x62, 0x70, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x48, 0x03, 0x52, 0x09, 0x69, 0x6f, 0x52, 0x65, 0x61, 0x64, 0x42,
+	0x70, 0x73, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0c, 0x69, 0x6f, 0x5f, 0x77, 0x72, 0x69, 0x74,
+	0x65, 0x5f, 0x62, 0x70, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x48, 0x04, 0x52, 0x0a, 0x69,
+	0x6f, 0x57, 0x72, 0x69, 0x74, 0x65, 0x42, 0x70, 0x73, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0c,
+	0x69, 0x6f, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x69, 0x6f, 0x70, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x48, 0x05, 0x52, 0x0a, 0x69, 0x6f, 0x52, 0x65, 0x61, 0x64, 0x49, 0x6f, 0x70, 0x73,
+	0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0d, 0x69, 0x6f, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f,
+	0x69, 0x6f, 0x70, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x48, 0x06, 0x52, 0x0b, 0x69, 0x6f,
+	0x57, 0x72, 0x69, 0x74, 0x65, 0x49, 0x6f, 0x70, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08,
+	0x70, 0x69, 0x64, 0x73, 0x5f, 0x6d, 0x61, 0x78, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x48, 0x07,
+	0x52, 0x07, 0x70, 0x69, 0x64, 0x73, 0x4d, 0x61, 0x78, 0x88, 0x01, 0x01, 0x42, 0x0f, 0x0a, 0x0d,
+	0x5f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x42, 0x11, 0x0a,
+	0x0f, 0x5f, 0x63, 0x70, 0x75, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x5f, 0x75, 0x73, 0x65, 0x63,
+	0x42, 0x12, 0x0a, 0x10, 0x5f, 0x63, 0x70, 0x75, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x5f,
+	0x75, 0x73, 0x65, 0x63, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x69, 0x6f, 0x5f, 0x72, 0x65, 0x61, 0x64,
+	0x5f, 0x62, 0x70, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x69, 0x6f, 0x5f, 0x77, 0x72, 0x69, 0x74,
+	0x65, 0x5f, 0x62, 0x70, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x69, 0x6f, 0x5f, 0x72, 0x65, 0x61,
+	0x64, 0x5f, 0x69, 0x6f, 0x70, 0x73, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x69, 0x6f, 0x5f, 0x77, 0x72,
+	0x69, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x70, 0x73, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x70, 0x69, 0x64,
+	0x73, 0x5f, 0x6d, 0x61, 0x78, 0x22, 0xad, 0x01, 0x0a, 0x13, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x52, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b,
+	0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x69, 0x72, 0x12, 0x10, 0x0a,
+	0x03, 0x65, 0x6e, 0x76, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x65, 0x6e, 0x76, 0x12,
+	0x14, 0x0a, 0x05, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x73, 0x74, 0x64, 0x69, 0x6e, 0x22, 0xa9, 0x01, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x65, 0x61, 0x6b, 0x5f,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0f, 0x70, 0x65, 0x61, 0x6b, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x63, 0x70, 0x75, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f,
+	0x75, 0x73, 0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x63, 0x70, 0x75, 0x54,
+	0x69, 0x6d, 0x65, 0x55, 0x73, 0x65, 0x63, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x6f, 0x5f, 0x72, 0x65,
+	0x61, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b,
+	0x69, 0x6f, 0x52, 0x65, 0x61, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x69,
+	0x6f, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0c, 0x69, 0x6f, 0x57, 0x72, 0x69, 0x74, 0x65, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x22, 0x35, 0x0a, 0x14, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x22, 0x96, 0x04, 0x0a, 0x15, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72,
+	0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x75,
+	0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x24, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f,
+	0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x0a, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x65,
+	0x78, 0x69, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x48, 0x01, 0x52, 0x06, 0x65,
+	0x78, 0x69, 0x74, 0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x6f, 0x73, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6c, 0x6f, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x6c, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12,
+	0x35, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x65,
+	0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a,
+	0x0a, 0x6f, 0x6f, 0x6d, 0x5f, 0x6b, 0x69, 0x6c, 0x6c, 0x65, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x6f, 0x6f, 0x6d, 0x4b, 0x69, 0x6c, 0x6c, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e,
+	0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x03, 0x70, 0x69, 0x64, 0x12, 0x30, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0e, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65,
+	0x63, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x65, 0x78, 0x69, 0x74, 0x65,
+	0x64, 0x22, 0x89, 0x01, 0x0a, 0x12, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x61,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x12,
+	0x3c, 0x0a, 0x0c, 0x67, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0b, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x22, 0x4e, 0x0a,
+	0x13, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x35, 0x0a,
+	0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65,
 	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
 	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x49, 0x64, 0x22, 0x4e, 0x0a, 0x13, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75,
-	0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x22, 0x35, 0x0a, 0x14, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4f,
-	0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
-	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x22, 0x2c, 0x0a, 0x12, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x0f, 0x0a, 0x0d, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x7c, 0x0a, 0x0e, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07,
-	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x3e, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x72, 0x65, 0x6d,
-	0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x08,
-	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x32, 0xa6, 0x03, 0x0a, 0x0a, 0x52, 0x65, 0x6d,
-	0x6f, 0x74, 0x65, 0x45, 0x78, 0x65, 0x63, 0x12, 0x41, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x12, 0x1a, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e,
-	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x0c, 0x53, 0x74,
-	0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x20, 0x2e, 0x72, 0x65, 0x6d,
-	0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x72,
+	0x6e, 0x64, 0x49, 0x64, 0x22, 0x50, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x9e, 0x04, 0x0a, 0x14, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x74, 0x61,
+	0x69, 0x6c, 0x12, 0x31, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x19, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63,
+	0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x06, 0x73,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x6f,
+	0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x61,
+	0x73, 0x74, 0x4c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x61, 0x73,
+	0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x30, 0x0a, 0x05,
+	0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x30,
+	0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c,
+	0x12, 0x2a, 0x0a, 0x11, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x73,
+	0x74, 0x64, 0x6f, 0x75, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x53, 0x6b, 0x69, 0x70, 0x53, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x12, 0x2a, 0x0a, 0x11,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x73, 0x74, 0x64, 0x65, 0x72,
+	0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x53, 0x6b,
+	0x69, 0x70, 0x53, 0x74, 0x64, 0x65, 0x72, 0x72, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f,
+	0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x4c, 0x69, 0x6e, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68,
+	0x12, 0x45, 0x0a, 0x11, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x69, 0x64, 0x6c, 0x65, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x6c, 0x69, 0x6e, 0x65, 0x49, 0x64, 0x6c, 0x65,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0xe9, 0x01, 0x0a, 0x12, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x16,
+	0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
+	0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
+	0x65, 0x78, 0x65, 0x63, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66,
+	0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x6b, 0x69, 0x70, 0x53, 0x74, 0x64, 0x6f,
+	0x75, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x73, 0x74, 0x64, 0x65, 0x72,
+	0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x6b, 0x69, 0x70, 0x53, 0x74, 0x64,
+	0x65, 0x72, 0x72, 0x22, 0x70, 0x0a, 0x18, 0x57, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x35,
+	0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x76, 0x61, 0x6c, 0x22, 0xe3, 0x01, 0x0a, 0x0c, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x63, 0x70, 0x75,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x75, 0x73, 0x65, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0b, 0x63, 0x70, 0x75, 0x54, 0x69, 0x6d, 0x65, 0x55, 0x73, 0x65, 0x63, 0x12, 0x22, 0x0a,
+	0x0d, 0x69, 0x6f, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x69, 0x6f, 0x52, 0x65, 0x61, 0x64, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x24, 0x0a, 0x0e, 0x69, 0x6f, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x69, 0x6f, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x69, 0x64, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x70, 0x69, 0x64, 0x73, 0x22, 0x0f, 0x0a, 0x0d, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x7c, 0x0a, 0x0e,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x3e, 0x0a, 0x08, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x72,
 	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
 	0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x50, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
-	0x1f, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x53, 0x74,
-	0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x20, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x53,
-	0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x56, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x12, 0x21, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65,
-	0x63, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
-	0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x55, 0x0a, 0x0d, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x21, 0x2e, 0x72, 0x65,
-	0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x2a, 0x2f, 0x0a, 0x0c, 0x4f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x4c,
+	0x4c, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x44, 0x4f, 0x55, 0x54, 0x10, 0x01, 0x12,
+	0x0a, 0x0a, 0x06, 0x53, 0x54, 0x44, 0x45, 0x52, 0x52, 0x10, 0x02, 0x32, 0xd7, 0x04, 0x0a, 0x0a,
+	0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x45, 0x78, 0x65, 0x63, 0x12, 0x41, 0x0a, 0x06, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x1a, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78,
+	0x65, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a,
+	0x0c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x20, 0x2e,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x72,
+	0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x22, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x12, 0x1f, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63,
+	0x2e, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65,
+	0x63, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x21, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
+	0x65, 0x78, 0x65, 0x63, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a,
+	0x0d, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x21,
 	0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x30,
-	0x01, 0x42, 0x1b, 0x5a, 0x19, 0x74, 0x65, 0x6c, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x5f, 0x65, 0x78,
-	0x65, 0x63, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x22, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x55, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x21, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
+	0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x30, 0x01, 0x12, 0x57, 0x0a, 0x11,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x25, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x30, 0x01, 0x42, 0x1b, 0x5a, 0x19, 0x74, 0x65, 0x6c, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78,
+	0x65, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -587,35 +1485,63 @@ func file_remote_exec_remote_exec_proto_rawDescGZIP() []byte {
 	return file_remote_exec_remote_exec_proto_rawDescData
 }
 
-var file_remote_exec_remote_exec_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_remote_exec_remote_exec_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_remote_exec_remote_exec_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_remote_exec_remote_exec_proto_goTypes = []interface{}{
-	(*StartCommandRequest)(nil),   // 0: remote_exec.StartCommandRequest
-	(*CommandStatusRequest)(nil),  // 1: remote_exec.CommandStatusRequest
-	(*CommandStatusResponse)(nil), // 2: remote_exec.CommandStatusResponse
-	(*StopCommandRequest)(nil),    // 3: remote_exec.StopCommandRequest
-	(*StopCommandResponse)(nil),   // 4: remote_exec.StopCommandResponse
-	(*CommandOutputRequest)(nil),  // 5: remote_exec.CommandOutputRequest
-	(*CommandOutputBlock)(nil),    // 6: remote_exec.CommandOutputBlock
-	(*StatusRequest)(nil),         // 7: remote_exec.StatusRequest
-	(*StatusResponse)(nil),        // 8: remote_exec.StatusResponse
+	(OutputStream)(0),                // 0: remote_exec.OutputStream
+	(*ResourceLimits)(nil),           // 1: remote_exec.ResourceLimits
+	(*StartCommandRequest)(nil),      // 2: remote_exec.StartCommandRequest
+	(*ResourceUsage)(nil),            // 3: remote_exec.ResourceUsage
+	(*CommandStatusRequest)(nil),     // 4: remote_exec.CommandStatusRequest
+	(*CommandStatusResponse)(nil),    // 5: remote_exec.CommandStatusResponse
+	(*StopCommandRequest)(nil),       // 6: remote_exec.StopCommandRequest
+	(*StopCommandResponse)(nil),      // 7: remote_exec.StopCommandResponse
+	(*DeleteCommandRequest)(nil),     // 8: remote_exec.DeleteCommandRequest
+	(*DeleteCommandResponse)(nil),    // 9: remote_exec.DeleteCommandResponse
+	(*CommandOutputRequest)(nil),     // 10: remote_exec.CommandOutputRequest
+	(*CommandOutputBlock)(nil),       // 11: remote_exec.CommandOutputBlock
+	(*WatchCommandStatsRequest)(nil), // 12: remote_exec.WatchCommandStatsRequest
+	(*CommandStats)(nil),             // 13: remote_exec.CommandStats
+	(*StatusRequest)(nil),            // 14: remote_exec.StatusRequest
+	(*StatusResponse)(nil),           // 15: remote_exec.StatusResponse
+	(*timestamppb.Timestamp)(nil),    // 16: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),      // 17: google.protobuf.Duration
 }
 var file_remote_exec_remote_exec_proto_depIdxs = []int32{
-	2, // 0: remote_exec.StatusResponse.commands:type_name -> remote_exec.CommandStatusResponse
-	7, // 1: remote_exec.RemoteExec.Status:input_type -> remote_exec.StatusRequest
-	0, // 2: remote_exec.RemoteExec.StartCommand:input_type -> remote_exec.StartCommandRequest
-	3, // 3: remote_exec.RemoteExec.StopCommand:input_type -> remote_exec.StopCommandRequest
-	1, // 4: remote_exec.RemoteExec.CommandStatus:input_type -> remote_exec.CommandStatusRequest
-	5, // 5: remote_exec.RemoteExec.CommandOutput:input_type -> remote_exec.CommandOutputRequest
-	8, // 6: remote_exec.RemoteExec.Status:output_type -> remote_exec.StatusResponse
-	2, // 7: remote_exec.RemoteExec.StartCommand:output_type -> remote_exec.CommandStatusResponse
-	4, // 8: remote_exec.RemoteExec.StopCommand:output_type -> remote_exec.StopCommandResponse
-	2, // 9: remote_exec.RemoteExec.CommandStatus:output_type -> remote_exec.CommandStatusResponse
-	6, // 10: remote_exec.RemoteExec.CommandOutput:output_type -> remote_exec.CommandOutputBlock
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: remote_exec.StartCommandRequest.limits:type_name -> remote_exec.ResourceLimits
+	16, // 1: remote_exec.CommandStatusResponse.start_time:type_name -> google.protobuf.Timestamp
+	16, // 2: remote_exec.CommandStatusResponse.end_time:type_name -> google.protobuf.Timestamp
+	17, // 3: remote_exec.CommandStatusResponse.duration:type_name -> google.protobuf.Duration
+	3,  // 4: remote_exec.CommandStatusResponse.usage:type_name -> remote_exec.ResourceUsage
+	17, // 5: remote_exec.StopCommandRequest.grace_period:type_name -> google.protobuf.Duration
+	0,  // 6: remote_exec.CommandOutputRequest.stream:type_name -> remote_exec.OutputStream
+	16, // 7: remote_exec.CommandOutputRequest.since:type_name -> google.protobuf.Timestamp
+	16, // 8: remote_exec.CommandOutputRequest.until:type_name -> google.protobuf.Timestamp
+	17, // 9: remote_exec.CommandOutputRequest.line_idle_timeout:type_name -> google.protobuf.Duration
+	0,  // 10: remote_exec.CommandOutputBlock.stream:type_name -> remote_exec.OutputStream
+	16, // 11: remote_exec.CommandOutputBlock.time:type_name -> google.protobuf.Timestamp
+	17, // 12: remote_exec.WatchCommandStatsRequest.interval:type_name -> google.protobuf.Duration
+	16, // 13: remote_exec.CommandStats.time:type_name -> google.protobuf.Timestamp
+	5,  // 14: remote_exec.StatusResponse.commands:type_name -> remote_exec.CommandStatusResponse
+	14, // 15: remote_exec.RemoteExec.Status:input_type -> remote_exec.StatusRequest
+	2,  // 16: remote_exec.RemoteExec.StartCommand:input_type -> remote_exec.StartCommandRequest
+	6,  // 17: remote_exec.RemoteExec.StopCommand:input_type -> remote_exec.StopCommandRequest
+	8,  // 18: remote_exec.RemoteExec.DeleteCommand:input_type -> remote_exec.DeleteCommandRequest
+	4,  // 19: remote_exec.RemoteExec.CommandStatus:input_type -> remote_exec.CommandStatusRequest
+	10, // 20: remote_exec.RemoteExec.CommandOutput:input_type -> remote_exec.CommandOutputRequest
+	12, // 21: remote_exec.RemoteExec.WatchCommandStats:input_type -> remote_exec.WatchCommandStatsRequest
+	15, // 22: remote_exec.RemoteExec.Status:output_type -> remote_exec.StatusResponse
+	5,  // 23: remote_exec.RemoteExec.StartCommand:output_type -> remote_exec.CommandStatusResponse
+	7,  // 24: remote_exec.RemoteExec.StopCommand:output_type -> remote_exec.StopCommandResponse
+	9,  // 25: remote_exec.RemoteExec.DeleteCommand:output_type -> remote_exec.DeleteCommandResponse
+	5,  // 26: remote_exec.RemoteExec.CommandStatus:output_type -> remote_exec.CommandStatusResponse
+	11, // 27: remote_exec.RemoteExec.CommandOutput:output_type -> remote_exec.CommandOutputBlock
+	13, // 28: remote_exec.RemoteExec.WatchCommandStats:output_type -> remote_exec.CommandStats
+	22, // [22:29] is the sub-list for method output_type
+	15, // [15:22] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_remote_exec_remote_exec_proto_init() }
@@ -625,7 +1551,7 @@ func file_remote_exec_remote_exec_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_remote_exec_remote_exec_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StartCommandRequest); i {
+			switch v := v.(*ResourceLimits); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -637,7 +1563,7 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 		file_remote_exec_remote_exec_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CommandStatusRequest); i {
+			switch v := v.(*StartCommandRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -649,7 +1575,7 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 		file_remote_exec_remote_exec_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CommandStatusResponse); i {
+			switch v := v.(*ResourceUsage); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -661,7 +1587,7 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 		file_remote_exec_remote_exec_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopCommandRequest); i {
+			switch v := v.(*CommandStatusRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -673,7 +1599,7 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 		file_remote_exec_remote_exec_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopCommandResponse); i {
+			switch v := v.(*CommandStatusResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -685,7 +1611,7 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 		file_remote_exec_remote_exec_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CommandOutputRequest); i {
+			switch v := v.(*StopCommandRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -697,7 +1623,7 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 		file_remote_exec_remote_exec_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CommandOutputBlock); i {
+			switch v := v.(*StopCommandResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -709,7 +1635,7 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 		file_remote_exec_remote_exec_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StatusRequest); i {
+			switch v := v.(*DeleteCommandRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -721,6 +1647,78 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 		file_remote_exec_remote_exec_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteCommandResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_exec_remote_exec_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandOutputRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_exec_remote_exec_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandOutputBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_exec_remote_exec_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchCommandStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_exec_remote_exec_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_exec_remote_exec_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_exec_remote_exec_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StatusResponse); i {
 			case 0:
 				return &v.state
@@ -733,19 +1731,21 @@ func file_remote_exec_remote_exec_proto_init() {
 			}
 		}
 	}
-	file_remote_exec_remote_exec_proto_msgTypes[2].OneofWrappers = []interface{}{}
+	file_remote_exec_remote_exec_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_remote_exec_remote_exec_proto_msgTypes[4].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_remote_exec_remote_exec_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   9,
+			NumEnums:      1,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_remote_exec_remote_exec_proto_goTypes,
 		DependencyIndexes: file_remote_exec_remote_exec_proto_depIdxs,
+		EnumInfos:         file_remote_exec_remote_exec_proto_enumTypes,
 		MessageInfos:      file_remote_exec_remote_exec_proto_msgTypes,
 	}.Build()
 	File_remote_exec_remote_exec_proto = out.File
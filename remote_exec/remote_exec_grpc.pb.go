@@ -21,8 +21,10 @@ type RemoteExecClient interface {
 	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 	StartCommand(ctx context.Context, in *StartCommandRequest, opts ...grpc.CallOption) (*CommandStatusResponse, error)
 	StopCommand(ctx context.Context, in *StopCommandRequest, opts ...grpc.CallOption) (*StopCommandResponse, error)
+	DeleteCommand(ctx context.Context, in *DeleteCommandRequest, opts ...grpc.CallOption) (*DeleteCommandResponse, error)
 	CommandStatus(ctx context.Context, in *CommandStatusRequest, opts ...grpc.CallOption) (*CommandStatusResponse, error)
 	CommandOutput(ctx context.Context, in *CommandOutputRequest, opts ...grpc.CallOption) (RemoteExec_CommandOutputClient, error)
+	WatchCommandStats(ctx context.Context, in *WatchCommandStatsRequest, opts ...grpc.CallOption) (RemoteExec_WatchCommandStatsClient, error)
 }
 
 type remoteExecClient struct {
@@ -60,6 +62,15 @@ func (c *remoteExecClient) StopCommand(ctx context.Context, in *StopCommandReque
 	return out, nil
 }
 
+func (c *remoteExecClient) DeleteCommand(ctx context.Context, in *DeleteCommandRequest, opts ...grpc.CallOption) (*DeleteCommandResponse, error) {
+	out := new(DeleteCommandResponse)
+	err := c.cc.Invoke(ctx, "/remote_exec.RemoteExec/DeleteCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *remoteExecClient) CommandStatus(ctx context.Context, in *CommandStatusRequest, opts ...grpc.CallOption) (*CommandStatusResponse, error) {
 	out := new(CommandStatusResponse)
 	err := c.cc.Invoke(ctx, "/remote_exec.RemoteExec/CommandStatus", in, out, opts...)
@@ -101,6 +112,38 @@ func (x *remoteExecCommandOutputClient) Recv() (*CommandOutputBlock, error) {
 	return m, nil
 }
 
+func (c *remoteExecClient) WatchCommandStats(ctx context.Context, in *WatchCommandStatsRequest, opts ...grpc.CallOption) (RemoteExec_WatchCommandStatsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RemoteExec_ServiceDesc.Streams[1], "/remote_exec.RemoteExec/WatchCommandStats", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &remoteExecWatchCommandStatsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RemoteExec_WatchCommandStatsClient interface {
+	Recv() (*CommandStats, error)
+	grpc.ClientStream
+}
+
+type remoteExecWatchCommandStatsClient struct {
+	grpc.ClientStream
+}
+
+func (x *remoteExecWatchCommandStatsClient) Recv() (*CommandStats, error) {
+	m := new(CommandStats)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RemoteExecServer is the server API for RemoteExec service.
 // All implementations must embed UnimplementedRemoteExecServer
 // for forward compatibility
@@ -108,8 +151,10 @@ type RemoteExecServer interface {
 	Status(context.Context, *StatusRequest) (*StatusResponse, error)
 	StartCommand(context.Context, *StartCommandRequest) (*CommandStatusResponse, error)
 	StopCommand(context.Context, *StopCommandRequest) (*StopCommandResponse, error)
+	DeleteCommand(context.Context, *DeleteCommandRequest) (*DeleteCommandResponse, error)
 	CommandStatus(context.Context, *CommandStatusRequest) (*CommandStatusResponse, error)
 	CommandOutput(*CommandOutputRequest, RemoteExec_CommandOutputServer) error
+	WatchCommandStats(*WatchCommandStatsRequest, RemoteExec_WatchCommandStatsServer) error
 	mustEmbedUnimplementedRemoteExecServer()
 }
 
@@ -126,12 +171,18 @@ func (UnimplementedRemoteExecServer) StartCommand(context.Context, *StartCommand
 func (UnimplementedRemoteExecServer) StopCommand(context.Context, *StopCommandRequest) (*StopCommandResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StopCommand not implemented")
 }
+func (UnimplementedRemoteExecServer) DeleteCommand(context.Context, *DeleteCommandRequest) (*DeleteCommandResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCommand not implemented")
+}
 func (UnimplementedRemoteExecServer) CommandStatus(context.Context, *CommandStatusRequest) (*CommandStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CommandStatus not implemented")
 }
 func (UnimplementedRemoteExecServer) CommandOutput(*CommandOutputRequest, RemoteExec_CommandOutputServer) error {
 	return status.Errorf(codes.Unimplemented, "method CommandOutput not implemented")
 }
+func (UnimplementedRemoteExecServer) WatchCommandStats(*WatchCommandStatsRequest, RemoteExec_WatchCommandStatsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchCommandStats not implemented")
+}
 func (UnimplementedRemoteExecServer) mustEmbedUnimplementedRemoteExecServer() {}
 
 // UnsafeRemoteExecServer may be embedded to opt out of forward compatibility for this service.
@@ -199,6 +250,24 @@ func _RemoteExec_StopCommand_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RemoteExec_DeleteCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemoteExecServer).DeleteCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote_exec.RemoteExec/DeleteCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemoteExecServer).DeleteCommand(ctx, req.(*DeleteCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _RemoteExec_CommandStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CommandStatusRequest)
 	if err := dec(in); err != nil {
@@ -238,6 +307,27 @@ func (x *remoteExecCommandOutputServer) Send(m *CommandOutputBlock) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _RemoteExec_WatchCommandStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchCommandStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RemoteExecServer).WatchCommandStats(m, &remoteExecWatchCommandStatsServer{stream})
+}
+
+type RemoteExec_WatchCommandStatsServer interface {
+	Send(*CommandStats) error
+	grpc.ServerStream
+}
+
+type remoteExecWatchCommandStatsServer struct {
+	grpc.ServerStream
+}
+
+func (x *remoteExecWatchCommandStatsServer) Send(m *CommandStats) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // RemoteExec_ServiceDesc is the grpc.ServiceDesc for RemoteExec service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -257,6 +347,10 @@ var RemoteExec_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StopCommand",
 			Handler:    _RemoteExec_StopCommand_Handler,
 		},
+		{
+			MethodName: "DeleteCommand",
+			Handler:    _RemoteExec_DeleteCommand_Handler,
+		},
 		{
 			MethodName: "CommandStatus",
 			Handler:    _RemoteExec_CommandStatus_Handler,
@@ -268,6 +362,11 @@ var RemoteExec_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _RemoteExec_CommandOutput_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchCommandStats",
+			Handler:       _RemoteExec_WatchCommandStats_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "remote_exec/remote_exec.proto",
 }